@@ -0,0 +1,103 @@
+package filterweb
+
+import (
+	"context"
+	"log/slog"
+	tmplText "text/template"
+	"time"
+)
+
+type CacheConfig struct {
+	Filter
+	Filters    []Config      // filters to cache
+	Key        string        // cache key (template), default is whole input data
+	TTL        time.Duration // time to live
+	Stale      time.Duration // serve expired data while revalidating
+	MaxEntries int           // max number of entries, default 1000, 0 for no limit
+	MaxBytes   int64         // max total size of entries, 0 for no limit
+	tmplkey    *tmplText.Template
+	pipeline   *Pipeline
+	cache      *Cache
+}
+
+func (cc *CacheConfig) New() Filter {
+	return &CacheConfig{}
+}
+
+func (cc *CacheConfig) Name() string {
+	return "cache"
+}
+
+func (cc *CacheConfig) Accepts() []string {
+	return []string{}
+}
+
+func (cc *CacheConfig) OutputContentType(input string) string {
+	return cc.pipeline.OutputContentType(input)
+}
+
+func (cc *CacheConfig) subPipelines() []*Pipeline {
+	return []*Pipeline{cc.pipeline}
+}
+
+func (cc *CacheConfig) Prep(config Config, data Data) (err error) {
+	// defaults
+	cc.MaxEntries = 1000
+	if err = decodeParams(config.Params, cc); err != nil {
+		slog.Error("mapstructure decode", "type", cc.Name(), "params", config.Params)
+		return err
+	}
+	// mandatory
+	if len(cc.Filters) == 0 {
+		slog.Error("cache filter requires 'filters' parameter")
+		return ErrMissingParams
+	}
+	if cc.TTL <= 0 || cc.Stale < 0 {
+		slog.Error("cache filter requires positive 'ttl'", "ttl", cc.TTL, "stale", cc.Stale)
+		return ErrInvalidParams
+	}
+	if cc.Key != "" {
+		if cc.tmplkey, err = parseText("key", cc.Key); err != nil {
+			slog.Error("key template parse error", "template", cc.Key, "error", err)
+			return err
+		}
+	}
+	if cc.pipeline, err = Compile(cc.Filters); err != nil {
+		return err
+	}
+	cc.cache = NewCache(cc.MaxEntries, cc.MaxBytes)
+	return nil
+}
+
+func (cc *CacheConfig) Process(data Data) (Data, error) {
+	return cc.ProcessContext(context.Background(), data)
+}
+
+func (cc *CacheConfig) ProcessContext(ctx context.Context, data Data) (Data, error) {
+	var key string
+	if cc.tmplkey != nil {
+		var err error
+		if key, err = renderText(ctx, cc.tmplkey, data.Data); err != nil {
+			return data, err
+		}
+	} else {
+		buf, err := data.Bytes()
+		if err != nil {
+			// cannot make a key, do not share results with other inputs
+			slog.Debug("cache key encode error, not cached", "contenttype", data.ContentType, "error", err)
+			return cc.pipeline.Process(ctx, data)
+		}
+		key = data.ContentType + "\n" + string(buf)
+	}
+	return cc.cache.Do(ctx, key, cc.TTL, cc.Stale, func(ctx context.Context) (Data, error) {
+		return cc.pipeline.Process(ctx, data)
+	})
+}
+
+func (cc *CacheConfig) Post(config Config, data Data) error {
+	return nil
+}
+
+func init() {
+	RegisterFilter(&CacheConfig{})
+}
@@ -0,0 +1,76 @@
+package filterweb
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCache_Process(t *testing.T) {
+	var hits atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id":%q}`, r.URL.Query().Get("id"))
+	}))
+	defer ts.Close()
+	cc := &CacheConfig{}
+	cfg := Config{Params: map[string]any{
+		"Key": "{{.id}}",
+		"TTL": "1m",
+		"Filters": []map[string]any{
+			{"Name": "http", "Params": map[string]any{"Url": ts.URL, "Query": map[string]string{"id": "{{.id}}"}}},
+		},
+	}}
+	if err := cc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	for _, id := range []string{"a", "b", "a", "a"} {
+		out, err := cc.Process(Data{ContentType: "application/json", Data: map[string]any{"id": id}})
+		if err != nil {
+			t.Fatalf("Process failed: %v", err)
+		}
+		if out.Data.(map[string]any)["id"] != id {
+			t.Fatalf("unexpected output: %v", out.Data)
+		}
+	}
+	if hits.Load() != 2 {
+		t.Fatalf("unexpected hits: %d", hits.Load())
+	}
+}
+
+func TestCache_Prep_InvalidTTL(t *testing.T) {
+	cc := &CacheConfig{}
+	cfg := Config{Params: map[string]any{
+		"Filters": []map[string]any{{"Name": "constant", "Params": map[string]any{"Data": "x"}}},
+	}}
+	if err := cc.Prep(cfg, Data{}); err != ErrInvalidParams {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestCache_Process_EncodeError(t *testing.T) {
+	cc := &CacheConfig{}
+	cfg := Config{Params: map[string]any{
+		"TTL":     "1m",
+		"Filters": []map[string]any{{"Name": "jq", "Params": map[string]any{"Expression": ".a"}}},
+	}}
+	if err := cc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	// map[string]any cannot be encoded as dotenv
+	for _, a := range []int{1, 2} {
+		out, err := cc.Process(Data{ContentType: "text/dotenv", Data: map[string]any{"a": a}})
+		if err != nil {
+			t.Fatalf("Process failed: %v", err)
+		}
+		if v := out.Data.([]any)[0]; v != a {
+			t.Fatalf("unexpected output: %v", v)
+		}
+	}
+	if cc.cache.Len() != 0 {
+		t.Fatalf("unexpected cache length: %d", cc.cache.Len())
+	}
+}
@@ -0,0 +1,87 @@
+package filterweb
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+)
+
+// maxCallDepth limits nested calls, to detect recursive pipelines
+const maxCallDepth = 32
+
+type pipelinesKey struct{}
+type callDepthKey struct{}
+
+// WithPipelines returns context with named pipelines for call filter
+func WithPipelines(ctx context.Context, pipelines map[string]*Pipeline) context.Context {
+	return context.WithValue(ctx, pipelinesKey{}, pipelines)
+}
+
+// PipelinesFromContext returns named pipelines of the context
+func PipelinesFromContext(ctx context.Context) map[string]*Pipeline {
+	pipelines, _ := ctx.Value(pipelinesKey{}).(map[string]*Pipeline)
+	return pipelines
+}
+
+type CallConfig struct {
+	Filter
+	Pipeline string         // name of pipeline
+	Args     map[string]any // variables of the pipeline
+}
+
+func (cc *CallConfig) New() Filter {
+	return &CallConfig{}
+}
+
+func (cc *CallConfig) Name() string {
+	return "call"
+}
+
+func (cc *CallConfig) Accepts() []string {
+	return []string{}
+}
+
+func (cc *CallConfig) Prep(config Config, data Data) error {
+	err := decodeParams(config.Params, cc)
+	if err != nil {
+		return err
+	}
+	// mandatory
+	if cc.Pipeline == "" {
+		slog.Error("call filter requires 'pipeline' parameter")
+		return ErrMissingParams
+	}
+	return nil
+}
+
+func (cc *CallConfig) Process(data Data) (Data, error) {
+	return cc.ProcessContext(context.Background(), data)
+}
+
+func (cc *CallConfig) ProcessContext(ctx context.Context, data Data) (Data, error) {
+	pipeline, ok := PipelinesFromContext(ctx)[cc.Pipeline]
+	if !ok {
+		slog.Error("pipeline not found", "pipeline", cc.Pipeline)
+		return data, ErrPipelineNotFound
+	}
+	depth, _ := ctx.Value(callDepthKey{}).(int)
+	if depth >= maxCallDepth {
+		slog.Error("too deep pipeline call", "pipeline", cc.Pipeline, "depth", depth)
+		return data, ErrCallDepth
+	}
+	ctx = context.WithValue(ctx, callDepthKey{}, depth+1)
+	slog.Debug("call pipeline", "pipeline", cc.Pipeline, "args", cc.Args, "depth", depth+1)
+	return pipeline.Process(WithVars(ctx, cc.Args), data)
+}
+
+func (cc *CallConfig) definedVars() []string { return slices.Collect(maps.Keys(cc.Args)) }
+func (cc *CallConfig) usedVars() []string    { return nil }
+
+func (cc *CallConfig) Post(config Config, data Data) error {
+	return nil
+}
+
+func init() {
+	RegisterFilter(&CallConfig{})
+}
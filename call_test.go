@@ -0,0 +1,64 @@
+package filterweb
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCall_Process(t *testing.T) {
+	cf := &ConfigFile{
+		Pipelines: map[string][]Config{
+			"greet": {
+				{Name: "template", Params: map[string]any{"Content": `{{var "greeting"}} {{.name}}`}},
+			},
+		},
+		Routes: []ConfigSchema{{Path: "/", Filters: []Config{
+			{Name: "call", Params: map[string]any{"Pipeline": "greet", "Args": map[string]any{"greeting": "Hello"}}},
+		}}},
+	}
+	compiled, err := cf.Compile()
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	ctx := WithPipelines(context.Background(), compiled.Pipelines)
+	out, err := compiled.Routes[0].Pipeline.Process(ctx, Data{ContentType: "application/json", Data: map[string]any{"name": "Alice"}})
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if out.String() != "Hello Alice" {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
+
+func TestCall_Process_NotFound(t *testing.T) {
+	cc := &CallConfig{}
+	if err := cc.Prep(Config{Params: map[string]any{"Pipeline": "missing"}}, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	if _, err := cc.Process(Data{}); err != ErrPipelineNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestCall_Process_Recursive(t *testing.T) {
+	cf := &ConfigFile{Pipelines: map[string][]Config{
+		"loop": {{Name: "call", Params: map[string]any{"Pipeline": "loop"}}},
+	}}
+	compiled, err := cf.Compile()
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	ctx := WithPipelines(context.Background(), compiled.Pipelines)
+	_, err = compiled.Pipelines["loop"].Process(ctx, Data{})
+	if !errors.Is(err, ErrCallDepth) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestCall_Prep_MissingParams(t *testing.T) {
+	cc := &CallConfig{}
+	if err := cc.Prep(Config{Params: map[string]any{}}, Data{}); err != ErrMissingParams {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
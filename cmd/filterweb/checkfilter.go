@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 
@@ -19,8 +20,14 @@ func (cf *CheckFilter) Execute(args []string) error {
 		slog.Error("fail to load config file", "path", globalOption.Config, "error", err)
 		return err
 	}
-	for _, config := range configData {
-		fdata, err := filterweb.ProcessFilters(config.Filters)
+	for _, config := range configData.Routes {
+		ctx := filterweb.WithPipelines(context.Background(), configData.Pipelines)
+		cancel := func() {}
+		if config.Timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, config.Timeout)
+		}
+		fdata, err := config.Process(ctx, filterweb.Data{})
+		cancel()
 		if !cf.HideCt {
 			fmt.Printf("%s %s\n", config.Method, config.Path)
 		}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/wtnb75/go-filterweb"
+)
+
+// certCheckInterval is minimum interval to check certificate file changes
+const certCheckInterval = time.Second
+
+// listen opens listener. "unix:/path.sock" listens on unix domain socket.
+func (s *WebServer) listen() (net.Listener, error) {
+	path, ok := strings.CutPrefix(s.Listen, "unix:")
+	if !ok {
+		return net.Listen("tcp", s.Listen)
+	}
+	mode, err := strconv.ParseUint(s.SocketMode, 8, 32)
+	if err != nil {
+		slog.Error("invalid socket mode", "mode", s.SocketMode, "error", err)
+		return nil, err
+	}
+	if st, err := os.Lstat(path); err == nil && st.Mode()&os.ModeSocket != 0 {
+		// remove only stale socket of previous run, nobody is listening on it
+		conn, err := net.Dial("unix", path)
+		if err == nil {
+			conn.Close()
+			slog.Error("socket is in use", "path", path)
+			return nil, fmt.Errorf("%s: %w", path, syscall.EADDRINUSE)
+		}
+		if !errors.Is(err, syscall.ECONNREFUSED) {
+			slog.Error("cannot check socket", "path", path, "error", err)
+			return nil, err
+		}
+		slog.Debug("remove old socket", "path", path)
+		if err = os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.Chmod(path, os.FileMode(mode)); err != nil {
+		slog.Error("chmod socket", "path", path, "mode", s.SocketMode, "error", err)
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// tlsConfig makes TLS server config, nil if TLS is not enabled
+func (s *WebServer) tlsConfig() (*tls.Config, error) {
+	if s.TLSCert == "" && s.TLSKey == "" {
+		if s.TLSClientCA != "" {
+			slog.Error("tls-client-ca requires tls-cert and tls-key")
+			return nil, filterweb.ErrTLSConfig
+		}
+		return nil, nil
+	}
+	if s.TLSCert == "" || s.TLSKey == "" {
+		slog.Error("both tls-cert and tls-key are required", "cert", s.TLSCert, "key", s.TLSKey)
+		return nil, filterweb.ErrTLSConfig
+	}
+	loader := &certLoader{certFile: s.TLSCert, keyFile: s.TLSKey}
+	if _, err := loader.load(); err != nil {
+		return nil, err
+	}
+	res := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: loader.GetCertificate,
+	}
+	if s.TLSClientCA != "" {
+		pem, err := os.ReadFile(s.TLSClientCA)
+		if err != nil {
+			slog.Error("read client CA file", "path", s.TLSClientCA, "error", err)
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			slog.Error("no certificate in client CA file", "path", s.TLSClientCA)
+			return nil, fmt.Errorf("%w: no certificate in %s", filterweb.ErrTLSConfig, s.TLSClientCA)
+		}
+		res.ClientCAs = pool
+		res.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return res, nil
+}
+
+// certLoader reloads certificate when the files are changed
+type certLoader struct {
+	certFile string
+	keyFile  string
+	mu       sync.Mutex
+	cert     *tls.Certificate
+	mtime    time.Time // newer one of cert and key
+	checked  time.Time
+}
+
+// modTime returns newer modification time of cert and key
+func (cl *certLoader) modTime() (time.Time, error) {
+	var res time.Time
+	for _, fn := range []string{cl.certFile, cl.keyFile} {
+		st, err := os.Stat(fn)
+		if err != nil {
+			return res, err
+		}
+		if st.ModTime().After(res) {
+			res = st.ModTime()
+		}
+	}
+	return res, nil
+}
+
+// load reads certificate if changed, current certificate is kept on errors
+func (cl *certLoader) load() (*tls.Certificate, error) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	if cl.cert != nil && time.Since(cl.checked) < certCheckInterval {
+		return cl.cert, nil
+	}
+	cl.checked = time.Now()
+	mtime, err := cl.modTime()
+	if err != nil {
+		slog.Error("stat certificate", "cert", cl.certFile, "key", cl.keyFile, "error", err)
+		return cl.cert, err
+	}
+	if cl.cert != nil && mtime.Equal(cl.mtime) {
+		return cl.cert, nil
+	}
+	cert, err := tls.LoadX509KeyPair(cl.certFile, cl.keyFile)
+	if err != nil {
+		slog.Error("load certificate", "cert", cl.certFile, "key", cl.keyFile, "error", err)
+		return cl.cert, err
+	}
+	if cl.cert != nil {
+		slog.Info("certificate reloaded", "cert", cl.certFile)
+	}
+	cl.cert, cl.mtime = &cert, mtime
+	return cl.cert, nil
+}
+
+func (cl *certLoader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cert, err := cl.load()
+	if cert != nil {
+		// keep serving with old certificate
+		return cert, nil
+	}
+	return nil, err
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// writeServerCert writes self-signed certificate and key of name as PEM files
+func writeServerCert(t *testing.T, certfile, keyfile, name string, mtime time.Time) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyder, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	if err = os.WriteFile(certfile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		t.Fatalf("write cert: %v", err)
+	}
+	if err = os.WriteFile(keyfile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyder}), 0600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+	// mtime may not change within a short time
+	os.Chtimes(certfile, mtime, mtime)
+	os.Chtimes(keyfile, mtime, mtime)
+}
+
+// commonName returns subject of the loaded certificate
+func commonName(t *testing.T, cl *certLoader) string {
+	t.Helper()
+	cert, err := cl.GetCertificate(nil)
+	if err != nil {
+		t.Fatalf("GetCertificate failed: %v", err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	return leaf.Subject.CommonName
+}
+
+func TestCertLoader_Reload(t *testing.T) {
+	dir := t.TempDir()
+	certfile, keyfile := filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key")
+	now := time.Now()
+	writeServerCert(t, certfile, keyfile, "first", now)
+	cl := &certLoader{certFile: certfile, keyFile: keyfile}
+	if name := commonName(t, cl); name != "first" {
+		t.Fatalf("unexpected certificate: %s", name)
+	}
+
+	writeServerCert(t, certfile, keyfile, "second", now.Add(time.Minute))
+	if name := commonName(t, cl); name != "first" {
+		t.Fatalf("checked within interval: %s", name)
+	}
+	cl.checked = time.Time{}
+	if name := commonName(t, cl); name != "second" {
+		t.Fatalf("not reloaded: %s", name)
+	}
+
+	// broken files, keep serving with current certificate
+	if err := os.WriteFile(certfile, []byte("broken"), 0644); err != nil {
+		t.Fatalf("write cert: %v", err)
+	}
+	os.Chtimes(certfile, now.Add(2*time.Minute), now.Add(2*time.Minute))
+	cl.checked = time.Time{}
+	if _, err := cl.load(); err == nil {
+		t.Fatalf("expected load error")
+	}
+	cl.checked = time.Time{}
+	if name := commonName(t, cl); name != "second" {
+		t.Fatalf("unexpected certificate: %s", name)
+	}
+}
+
+func TestWebServer_TLSConfig_Invalid(t *testing.T) {
+	for _, s := range []*WebServer{
+		{TLSCert: "cert.pem"},
+		{TLSClientCA: "ca.pem"},
+		{TLSCert: "notfound.pem", TLSKey: "notfound.key"},
+	} {
+		if _, err := s.tlsConfig(); err == nil {
+			t.Fatalf("expected error: %+v", s)
+		}
+	}
+}
+
+func TestListen_Unix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.sock")
+	s := &WebServer{Listen: "unix:" + path, SocketMode: "0600"}
+	ln, err := s.listen()
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	st, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat failed: %v", err)
+	}
+	if st.Mode()&os.ModeSocket == 0 || st.Mode().Perm() != 0600 {
+		t.Fatalf("unexpected mode: %v", st.Mode())
+	}
+
+	// in use, the socket must not be removed
+	if _, err := s.listen(); !errors.Is(err, syscall.EADDRINUSE) {
+		t.Fatalf("expected address in use: %v", err)
+	}
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("socket is removed: %v", err)
+	}
+	conn.Close()
+
+	// stale socket of previous run
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	ln.Close()
+	if _, err := os.Lstat(path); err != nil {
+		t.Fatalf("socket file is removed: %v", err)
+	}
+	ln, err = s.listen()
+	if err != nil {
+		t.Fatalf("listen on stale socket failed: %v", err)
+	}
+	ln.Close()
+}
+
+func TestListen_Unix_InvalidMode(t *testing.T) {
+	s := &WebServer{Listen: "unix:" + filepath.Join(t.TempDir(), "test.sock"), SocketMode: "rw"}
+	if _, err := s.listen(); err == nil {
+		t.Fatalf("expected error")
+	}
+}
@@ -1,9 +1,13 @@
 package main
 
 import (
-	"io"
+	"fmt"
 	"log/slog"
+	"maps"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/goccy/go-yaml"
 	"github.com/jessevdk/go-flags"
@@ -30,25 +34,89 @@ func init_log() {
 	}
 }
 
-func load_config(fn string) ([]filterweb.ConfigSchema, error) {
-	var configData []filterweb.ConfigSchema
-	slog.Debug("config file", "path", globalOption.Config)
-	if f, err := os.Open(string(globalOption.Config)); err == nil {
-		defer f.Close()
-		if data, err := io.ReadAll(f); err == nil {
-			if err = yaml.Unmarshal(data, &configData); err != nil {
-				slog.Error("failed to parse config file", "error", err)
-				return nil, err
+// load_config reads config file, validates and prepares all routes. every problem is logged.
+func load_config(fn string) (*filterweb.CompiledConfig, error) {
+	compiled, _, err := load_config_files(fn)
+	return compiled, err
+}
+
+// load_config_files is load_config which also returns paths of read files including includes
+func load_config_files(fn string) (*filterweb.CompiledConfig, []string, error) {
+	res := &filterweb.ConfigFile{Pipelines: map[string][]filterweb.Config{}}
+	loaded := map[string]bool{}
+	if err := load_config_file(fn, res, nil, loaded); err != nil {
+		return nil, nil, err
+	}
+	files := slices.Sorted(maps.Keys(loaded))
+	compiled, err := res.Compile()
+	if err != nil {
+		if errs, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, e := range errs.Unwrap() {
+				slog.Error("invalid config", "error", e)
 			}
 		} else {
-			slog.Error("failed to read config file", "error", err)
-			return nil, err
+			slog.Error("invalid config", "error", err)
+		}
+		return nil, nil, err
+	}
+	return compiled, files, nil
+}
+
+// load_config_file reads fn and its includes into res. stack is used to detect include cycle.
+func load_config_file(fn string, res *filterweb.ConfigFile, stack []string, loaded map[string]bool) error {
+	slog.Debug("config file", "path", fn)
+	abspath, err := filepath.Abs(fn)
+	if err != nil {
+		slog.Error("invalid config path", "path", fn, "error", err)
+		return err
+	}
+	if slices.Contains(stack, abspath) {
+		slog.Error("include cycle", "path", abspath, "stack", stack)
+		return fmt.Errorf("%w: %s", filterweb.ErrIncludeCycle, strings.Join(append(stack, abspath), " -> "))
+	}
+	if loaded[abspath] {
+		slog.Debug("already loaded", "path", abspath)
+		return nil
+	}
+	loaded[abspath] = true
+	stack = append(stack, abspath)
+	data, err := os.ReadFile(abspath)
+	if err != nil {
+		slog.Error("failed to read config file", "path", abspath, "error", err)
+		return err
+	}
+	var cfg filterweb.ConfigFile
+	if err = yaml.Unmarshal(data, &cfg.Routes); err != nil {
+		// not a list of routes
+		if err = yaml.Unmarshal(data, &cfg); err != nil {
+			slog.Error("failed to parse config file", "path", abspath, "error", err)
+			return err
+		}
+	}
+	for _, inc := range cfg.Include {
+		if !filepath.IsAbs(inc) {
+			inc = filepath.Join(filepath.Dir(abspath), inc)
+		}
+		matches, err := filepath.Glob(inc)
+		if err != nil || len(matches) == 0 {
+			slog.Error("include file not found", "path", inc, "error", err)
+			return fmt.Errorf("include %s: %w", inc, os.ErrNotExist)
+		}
+		for _, m := range matches {
+			if err = load_config_file(m, res, stack, loaded); err != nil {
+				return err
+			}
+		}
+	}
+	for name, pipeline := range cfg.Pipelines {
+		if _, ok := res.Pipelines[name]; ok {
+			slog.Error("duplicate pipeline", "name", name, "path", abspath)
+			return fmt.Errorf("%w: duplicate pipeline %s", filterweb.ErrInvalidParams, name)
 		}
-	} else {
-		slog.Error("failed to open config file", "error", err)
-		return nil, err
+		res.Pipelines[name] = pipeline
 	}
-	return configData, nil
+	res.Routes = append(res.Routes, cfg.Routes...)
+	return nil
 }
 
 type SubCommand struct {
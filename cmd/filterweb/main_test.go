@@ -0,0 +1,136 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/wtnb75/go-filterweb"
+)
+
+// writeFiles writes files under dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		fn := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(fn, []byte(content), 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+}
+
+func TestLoadConfig_RouteList(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"config.yaml": "- path: /a\n- path: /b\n  method: POST\n"})
+	compiled, files, err := load_config_files(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if len(compiled.Routes) != 2 || compiled.Routes[1].Method != "POST" {
+		t.Fatalf("unexpected routes: %+v", compiled.Routes)
+	}
+	if !reflect.DeepEqual(files, []string{filepath.Join(dir, "config.yaml")}) {
+		t.Fatalf("unexpected files: %v", files)
+	}
+}
+
+func TestLoadConfig_Include(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yaml": `
+include: [routes/*.yaml, common.yaml]
+pipelines:
+  main:
+    - name: call
+      params: {pipeline: common}
+routes:
+  - path: /main
+    filters:
+      - name: call
+        params: {pipeline: main}
+`,
+		"common.yaml": `
+include: [routes/a.yaml]
+pipelines:
+  common:
+    - name: constant
+      params: {contenttype: text/plain, data: x}
+`,
+		"routes/a.yaml": "- path: /a\n",
+		"routes/b.yaml": "- path: /b\n",
+		"routes/c.yml":  "- path: /not-matched\n",
+	})
+	compiled, files, err := load_config_files(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	var paths []string
+	for _, r := range compiled.Routes {
+		paths = append(paths, r.Path)
+	}
+	slices.Sort(paths)
+	if !reflect.DeepEqual(paths, []string{"/a", "/b", "/main"}) {
+		t.Fatalf("unexpected routes: %v", paths)
+	}
+	if _, ok := compiled.Pipelines["common"]; !ok {
+		t.Fatalf("pipeline in included file is missing: %v", compiled.Pipelines)
+	}
+	// a.yaml is included twice, loaded once
+	if len(files) != 4 {
+		t.Fatalf("unexpected files: %v", files)
+	}
+}
+
+func TestLoadConfig_IncludeNotFound(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"config.yaml": "include: [missing/*.yaml]\n"})
+	if _, _, err := load_config_files(filepath.Join(dir, "config.yaml")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestLoadConfig_IncludeCycle(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yaml": "include: [a.yaml]\n",
+		"a.yaml":      "include: [sub/b.yaml]\n",
+		"sub/b.yaml":  "include: [../a.yaml]\n",
+	})
+	if _, _, err := load_config_files(filepath.Join(dir, "config.yaml")); !errors.Is(err, filterweb.ErrIncludeCycle) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestLoadConfig_DuplicatePipeline(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yaml": "include: [other.yaml]\npipelines:\n  p:\n    - name: constant\n      params: {data: x}\n",
+		"other.yaml":  "pipelines:\n  p:\n    - name: constant\n      params: {data: y}\n",
+	})
+	if _, _, err := load_config_files(filepath.Join(dir, "config.yaml")); !errors.Is(err, filterweb.ErrInvalidParams) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestLoadConfig_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"syntax.yaml":  "routes: [\n",
+		"unknown.yaml": "- path: /a\n  filters:\n    - name: nosuchfilter\n",
+	})
+	if _, _, err := load_config_files(filepath.Join(dir, "syntax.yaml")); err == nil {
+		t.Fatalf("expected parse error")
+	}
+	if _, _, err := load_config_files(filepath.Join(dir, "unknown.yaml")); !errors.Is(err, filterweb.ErrFilterNotFound) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := load_config_files(filepath.Join(dir, "missing.yaml")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
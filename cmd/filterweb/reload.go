@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/wtnb75/go-filterweb"
+)
+
+// serverState is a loaded config, swapped as a whole on reload
+type serverState struct {
+	configData *filterweb.CompiledConfig
+	mux        *http.ServeMux
+	files      []string             // config files including includes
+	mtimes     map[string]time.Time // modification time of files
+}
+
+// load reads config and builds routes. current state is not changed.
+func (s *WebServer) load() (*serverState, error) {
+	configData, files, err := load_config_files(string(globalOption.Config))
+	if err != nil {
+		slog.Error("fail to load config file", "path", globalOption.Config, "error", err)
+		return nil, err
+	}
+	mux, err := s.buildMux(configData)
+	if err != nil {
+		return nil, err
+	}
+	return &serverState{configData: configData, mux: mux, files: files, mtimes: mtimes(files)}, nil
+}
+
+// reload loads config and swaps it. old config is kept on errors.
+func (s *WebServer) reload() error {
+	state, err := s.load()
+	if err != nil {
+		slog.Error("reload failed, keep current config", "error", err)
+		return err
+	}
+	old := s.state.Swap(state)
+	added, removed := diffRoutes(old.configData, state.configData)
+	for _, ptn := range added {
+		slog.Info("route added", "pattern", ptn)
+	}
+	for _, ptn := range removed {
+		slog.Info("route removed", "pattern", ptn)
+	}
+	slog.Info("config reloaded", "routes", len(state.configData.Routes), "added", len(added), "removed", len(removed))
+	return nil
+}
+
+// diffRoutes returns patterns of added and removed routes
+func diffRoutes(old, cur *filterweb.CompiledConfig) (added, removed []string) {
+	patterns := func(cfg *filterweb.CompiledConfig) map[string]bool {
+		res := map[string]bool{}
+		for _, r := range cfg.Routes {
+			res[pattern(r.ConfigSchema)] = true
+		}
+		return res
+	}
+	oldptn, curptn := patterns(old), patterns(cur)
+	for _, ptn := range slices.Sorted(maps.Keys(curptn)) {
+		if !oldptn[ptn] {
+			added = append(added, ptn)
+		}
+	}
+	for _, ptn := range slices.Sorted(maps.Keys(oldptn)) {
+		if !curptn[ptn] {
+			removed = append(removed, ptn)
+		}
+	}
+	return
+}
+
+// mtimes returns modification time of files, missing files are zero
+func mtimes(files []string) map[string]time.Time {
+	res := map[string]time.Time{}
+	for _, fn := range files {
+		if st, err := os.Stat(fn); err == nil {
+			res[fn] = st.ModTime()
+		} else {
+			res[fn] = time.Time{}
+		}
+	}
+	return res
+}
+
+// watch reloads config on SIGHUP, and on file change if Watch interval is set
+func (s *WebServer) watch(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	var tick <-chan time.Time
+	if s.Watch > 0 {
+		ticker := time.NewTicker(s.Watch)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	last := s.state.Load().mtimes
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			slog.Info("SIGHUP received, reloading config")
+		case <-tick:
+			cur := mtimes(s.state.Load().files)
+			if maps.Equal(cur, last) {
+				continue
+			}
+			// do not retry broken config until next change
+			last = cur
+			slog.Info("config file changed, reloading config")
+		}
+		if s.reload() == nil {
+			last = s.state.Load().mtimes
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/wtnb75/go-filterweb"
+)
+
+func TestDiffRoutes(t *testing.T) {
+	old := compile(t,
+		filterweb.ConfigSchema{Path: "/keep"},
+		filterweb.ConfigSchema{Path: "/gone", Method: "GET"},
+		filterweb.ConfigSchema{Path: "/method", Method: "GET"},
+	)
+	cur := compile(t,
+		filterweb.ConfigSchema{Path: "/keep", Method: "*"},
+		filterweb.ConfigSchema{Path: "/method", Method: "POST"},
+		filterweb.ConfigSchema{Path: "/new"},
+	)
+	added, removed := diffRoutes(old, cur)
+	if !reflect.DeepEqual(added, []string{"/new", "POST /method"}) {
+		t.Fatalf("unexpected added: %v", added)
+	}
+	if !reflect.DeepEqual(removed, []string{"GET /gone", "GET /method"}) {
+		t.Fatalf("unexpected removed: %v", removed)
+	}
+	if added, removed := diffRoutes(cur, cur); added != nil || removed != nil {
+		t.Fatalf("unexpected diff: %v %v", added, removed)
+	}
+}
+
+// writeConfig writes config file with mtime, routes respond with its path
+func writeConfig(t *testing.T, fn string, mtime time.Time, paths ...string) {
+	t.Helper()
+	var buf strings.Builder
+	for _, path := range paths {
+		fmt.Fprintf(&buf, "- path: %s\n  filters:\n    - name: constant\n      params: {contenttype: text/plain, data: %q}\n", path, path)
+	}
+	if err := os.WriteFile(fn, []byte(buf.String()), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	// mtime may not change within a short time
+	os.Chtimes(fn, mtime, mtime)
+}
+
+// useConfig sets config file of the command during the test
+func useConfig(t *testing.T, fn string) {
+	t.Helper()
+	prev := globalOption.Config
+	globalOption.Config = flags.Filename(fn)
+	t.Cleanup(func() { globalOption.Config = prev })
+}
+
+// routes returns patterns of the current state
+func routes(s *WebServer) []string {
+	var res []string
+	for _, r := range s.state.Load().configData.Routes {
+		res = append(res, pattern(r.ConfigSchema))
+	}
+	return res
+}
+
+func TestWebServer_Reload_KeepOnError(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "config.yaml")
+	now := time.Now()
+	writeConfig(t, fn, now, "/a")
+	useConfig(t, fn)
+	s := &WebServer{}
+	state, err := s.load()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	s.state.Store(state)
+
+	for i, content := range []string{
+		"- path: /b\n  filters:\n    - name: nosuchfilter\n",
+		"- path: /b\n  filters: [\n",
+		"- path: /{x}/b\n- path: /a/{y}\n",
+	} {
+		if err := os.WriteFile(fn, []byte(content), 0644); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+		if err := s.reload(); err == nil {
+			t.Fatalf("case %d: expected reload error", i)
+		}
+		if s.state.Load() != state {
+			t.Fatalf("case %d: state is changed", i)
+		}
+	}
+	writeConfig(t, fn, now, "/a", "/b")
+	if err := s.reload(); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if r := routes(s); !reflect.DeepEqual(r, []string{"/a", "/b"}) {
+		t.Fatalf("unexpected routes: %v", r)
+	}
+}
+
+func TestWebServer_Watch(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "config.yaml")
+	now := time.Now()
+	writeConfig(t, fn, now, "/a")
+	useConfig(t, fn)
+	s := &WebServer{Watch: 10 * time.Millisecond}
+	state, err := s.load()
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	s.state.Store(state)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.watch(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	waitRoutes := func(expected ...string) {
+		t.Helper()
+		for range 100 {
+			if reflect.DeepEqual(routes(s), expected) {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("not reloaded: %v", routes(s))
+	}
+
+	// same mtime, not reloaded
+	writeConfig(t, fn, now, "/a", "/same")
+	time.Sleep(50 * time.Millisecond)
+	if s.state.Load() != state {
+		t.Fatalf("reloaded without change of mtime")
+	}
+	writeConfig(t, fn, now.Add(time.Minute), "/a", "/b")
+	waitRoutes("/a", "/b")
+	writeConfig(t, fn, now.Add(2*time.Minute), "/c")
+	waitRoutes("/c")
+}
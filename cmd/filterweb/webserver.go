@@ -1,18 +1,38 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/wtnb75/go-filterweb"
 )
 
 type WebServer struct {
-	Listen     string `long:"listen" description:"listen address" default:":3000"`
-	configData []filterweb.ConfigSchema
+	Listen          string        `long:"listen" description:"listen address, unix:/path.sock for unix domain socket" default:":3000"`
+	SocketMode      string        `long:"socket-mode" description:"permission of unix domain socket (octal)" default:"0660"`
+	TLSCert         string        `long:"tls-cert" description:"server certificate file (PEM), reloaded on change"`
+	TLSKey          string        `long:"tls-key" description:"server key file (PEM), reloaded on change"`
+	TLSClientCA     string        `long:"tls-client-ca" description:"CA file (PEM) to verify client certificates"`
+	Watch           time.Duration `long:"watch" description:"interval to check config file changes, 0 to reload only on SIGHUP" default:"0s"`
+	ShutdownTimeout time.Duration `long:"shutdown-timeout" description:"max duration to drain in-flight requests on SIGTERM/SIGINT" default:"30s"`
+	ReadTimeout     time.Duration `long:"read-timeout" description:"max duration to read a request, 0 for no timeout" default:"0s"`
+	WriteTimeout    time.Duration `long:"write-timeout" description:"max duration to write a response, 0 for no timeout" default:"0s"`
+	IdleTimeout     time.Duration `long:"idle-timeout" description:"max duration to wait for the next request on keep-alive" default:"0s"`
+	MaxHeaderBytes  int           `long:"max-header-bytes" description:"max size of request headers" default:"1048576"`
+	MaxBodySize     int64         `long:"max-body-size" description:"max size of request body, 0 for no limit" default:"0"`
+	state           atomic.Pointer[serverState]
 }
 
 func (s *WebServer) accesslog(w http.ResponseWriter, r *http.Request, start time.Time, statuscode *int) {
@@ -59,57 +79,190 @@ func (s *WebServer) accesslog(w http.ResponseWriter, r *http.Request, start time
 	slog.Info(http.StatusText(*statuscode), headers...)
 }
 
+// statusWriter records the status code for the access log
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
 func (s *WebServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	slog.Debug("received request", "path", r.URL.Path, "method", r.Method)
-	statuscode := http.StatusOK
+	sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
 	start := time.Now()
-	defer s.accesslog(w, r, start, &statuscode)
-	for _, cfg := range s.configData {
-		if cfg.Path == r.URL.Path && cfg.Method == r.Method {
-			slog.Debug("matched config", "path", cfg.Path, "method", cfg.Method)
-			fdata, err := filterweb.ProcessFilters(cfg.Filters)
-			if err != nil {
-				statuscode = http.StatusInternalServerError
-				slog.Error("failed to process filters", "error", err)
-				http.Error(w, "Internal Server Error", statuscode)
-				return
+	defer s.accesslog(sw, r, start, &sw.status)
+	if s.MaxBodySize > 0 {
+		r.Body = http.MaxBytesReader(sw, r.Body, s.MaxBodySize)
+	}
+	s.state.Load().mux.ServeHTTP(sw, r)
+}
+
+func (s *WebServer) handler(cfg filterweb.CompiledRoute, pipelines map[string]*filterweb.Pipeline) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		slog.Debug("matched config", "path", cfg.Path, "method", cfg.Method, "pattern", r.Pattern)
+		reqdata, err := filterweb.RequestData(r)
+		if err != nil {
+			slog.Error("failed to read request", "error", err)
+			var maxerr *http.MaxBytesError
+			if errors.As(err, &maxerr) {
+				http.Error(w, "Request Entity Too Large", http.StatusRequestEntityTooLarge)
+			} else {
+				http.Error(w, "Bad Request", http.StatusBadRequest)
 			}
-			strbuf := fdata.String()
-			if strbuf == "" {
-				// error case
-				statuscode = http.StatusInternalServerError
-				slog.Error("failed to encode response data", "error", err)
-				http.Error(w, "Internal Server Error", statuscode)
-				return
+			return
+		}
+		ctx := filterweb.WithVars(r.Context(), map[string]any{"request": reqdata.Data})
+		ctx = filterweb.WithPipelines(ctx, pipelines)
+		if cfg.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+			defer cancel()
+		}
+		fdata, err := cfg.Process(ctx, reqdata)
+		if err != nil {
+			slog.Error("failed to process filters", "error", err)
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				http.Error(w, "Gateway Timeout", http.StatusGatewayTimeout)
+			} else {
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 			}
-			w.Header().Set("Content-Type", fdata.ContentType)
-			w.WriteHeader(statuscode)
-			_, err = w.Write([]byte(strbuf))
-			if err != nil {
-				slog.Error("failed to write response data", "error", err)
+			return
+		}
+		statuscode := http.StatusOK
+		if resp := fdata.Response; resp != nil {
+			for k, v := range resp.Headers {
+				w.Header()[k] = v
 			}
+			for _, c := range resp.Cookies {
+				http.SetCookie(w, c)
+			}
+			if resp.Status != 0 {
+				statuscode = resp.Status
+			}
+		}
+		if fdata.Data == nil {
+			// no body (e.g. redirect)
+			w.WriteHeader(statuscode)
+			return
+		}
+		buf, err := fdata.Bytes()
+		if err != nil {
+			slog.Error("failed to encode response data", "contenttype", fdata.ContentType, "error", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 			return
 		}
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", fdata.ContentType)
+		}
+		w.WriteHeader(statuscode)
+		_, err = w.Write(buf)
+		if err != nil {
+			slog.Error("failed to write response data", "error", err)
+		}
 	}
-	statuscode = http.StatusNotFound
-	slog.Warn("no matching config found", "path", r.URL.Path, "method", r.Method)
-	http.Error(w, "not found", statuscode)
 }
 
+// pattern converts a route to ServeMux pattern. empty method or "*" matches any method.
+// a path ending with slash matches exactly as before, not the subtree.
+func pattern(cfg filterweb.ConfigSchema) string {
+	path := cfg.Path
+	if strings.HasSuffix(path, "/") {
+		path += "{$}"
+	}
+	if cfg.Method == "" || cfg.Method == "*" {
+		return path
+	}
+	return cfg.Method + " " + path
+}
+
+// buildMux registers routes. conflicting patterns are reported as error.
+func (s *WebServer) buildMux(configData *filterweb.CompiledConfig) (mux *http.ServeMux, err error) {
+	mux = http.NewServeMux()
+	for _, cfg := range configData.Routes {
+		ptn := pattern(cfg.ConfigSchema)
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					slog.Error("invalid route", "pattern", ptn, "error", r)
+					err = fmt.Errorf("%w: %v", filterweb.ErrInvalidRoute, r)
+				}
+			}()
+			mux.Handle(ptn, s.handler(cfg, configData.Pipelines))
+		}()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return mux, nil
+}
+
+// shutdownSignals stop the webserver gracefully
+var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
 func (s *WebServer) Execute(args []string) error {
 	init_log()
-	var err error
-	s.configData, err = load_config(string(globalOption.Config))
+	state, err := s.load()
+	if err != nil {
+		return err
+	}
+	s.state.Store(state)
+	ctx, stop := signal.NotifyContext(context.Background(), shutdownSignals...)
+	defer stop()
+	// second signal terminates immediately
+	context.AfterFunc(ctx, stop)
+	go s.watch(ctx)
+	tlsconfig, err := s.tlsConfig()
 	if err != nil {
-		slog.Error("fail to load config file", "path", globalOption.Config, "error", err)
 		return err
 	}
-	hdl := http.NewServeMux()
-	hdl.Handle("/", s)
-	srv := http.Server{
-		Addr:    s.Listen,
-		Handler: hdl,
+	ln, err := s.listen()
+	if err != nil {
+		slog.Error("listen failed", "address", s.Listen, "error", err)
+		return err
+	}
+	return s.serve(ctx, ln, s.server(tlsconfig))
+}
+
+// server makes http server with the options
+func (s *WebServer) server(tlsconfig *tls.Config) *http.Server {
+	return &http.Server{
+		Addr:           s.Listen,
+		Handler:        s,
+		TLSConfig:      tlsconfig,
+		ReadTimeout:    s.ReadTimeout,
+		WriteTimeout:   s.WriteTimeout,
+		IdleTimeout:    s.IdleTimeout,
+		MaxHeaderBytes: s.MaxHeaderBytes,
+	}
+}
+
+// serve runs srv until ctx is done, then drains in-flight requests
+func (s *WebServer) serve(ctx context.Context, ln net.Listener, srv *http.Server) error {
+	slog.Info("starting webserver", "address", srv.Addr, "tls", srv.TLSConfig != nil)
+	errch := make(chan error, 1)
+	go func() {
+		if srv.TLSConfig != nil {
+			errch <- srv.ServeTLS(ln, "", "")
+		} else {
+			errch <- srv.Serve(ln)
+		}
+	}()
+	select {
+	case err := <-errch:
+		return err
+	case <-ctx.Done():
+	}
+	slog.Info("shutting down webserver", "timeout", s.ShutdownTimeout)
+	sctx, cancel := context.WithTimeout(context.Background(), s.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(sctx); err != nil {
+		slog.Error("shutdown failed", "error", err)
+		return err
 	}
-	slog.Info("starting webserver", "address", srv.Addr)
-	return srv.ListenAndServe()
+	slog.Info("webserver stopped")
+	return nil
 }
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/wtnb75/go-filterweb"
+)
+
+// compile compiles routes which respond with text of the pattern
+func compile(t *testing.T, routes ...filterweb.ConfigSchema) *filterweb.CompiledConfig {
+	t.Helper()
+	for i := range routes {
+		routes[i].Filters = []filterweb.Config{{Name: "constant", Params: map[string]any{
+			"ContentType": "text/plain", "Data": pattern(routes[i]),
+		}}}
+	}
+	cf := &filterweb.ConfigFile{Routes: routes}
+	res, err := cf.Compile()
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	return res
+}
+
+func TestBuildMux_Conflict(t *testing.T) {
+	s := &WebServer{}
+	for _, routes := range [][]filterweb.ConfigSchema{
+		{{Path: "/a", Method: "GET"}, {Path: "/a", Method: "GET"}},
+		{{Path: "/a"}, {Path: "/a", Method: "*"}},
+		{{Path: "/{x}/b"}, {Path: "/a/{y}"}},
+	} {
+		if _, err := s.buildMux(compile(t, routes...)); !errors.Is(err, filterweb.ErrInvalidRoute) {
+			t.Fatalf("expected conflict error for %v: %v", routes, err)
+		}
+	}
+}
+
+func TestBuildMux_AnyMethod(t *testing.T) {
+	s := &WebServer{}
+	mux, err := s.buildMux(compile(t,
+		filterweb.ConfigSchema{Path: "/any"},
+		filterweb.ConfigSchema{Path: "/star", Method: "*"},
+		filterweb.ConfigSchema{Path: "/get", Method: "GET"},
+		filterweb.ConfigSchema{Path: "/"},
+		filterweb.ConfigSchema{Path: "/dir/", Method: "GET"},
+		filterweb.ConfigSchema{Path: "/files/{path...}"},
+	))
+	if err != nil {
+		t.Fatalf("buildMux failed: %v", err)
+	}
+	tests := []struct {
+		method, path string
+		status       int
+		body         string
+	}{
+		{"GET", "/any", http.StatusOK, "/any"},
+		{"POST", "/any", http.StatusOK, "/any"},
+		{"DELETE", "/star", http.StatusOK, "/star"},
+		{"GET", "/get", http.StatusOK, "GET /get"},
+		{"POST", "/get", http.StatusMethodNotAllowed, ""},
+		{"GET", "/none", http.StatusNotFound, ""},
+		{"GET", "/", http.StatusOK, "/{$}"},
+		{"GET", "/nothing/here", http.StatusNotFound, ""},
+		{"GET", "/dir/", http.StatusOK, "GET /dir/{$}"},
+		{"GET", "/dir/sub", http.StatusNotFound, ""},
+		{"GET", "/files/a/b", http.StatusOK, "/files/{path...}"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, strings.NewReader("")))
+		if w.Code != tt.status {
+			t.Fatalf("%s %s: unexpected status: %d", tt.method, tt.path, w.Code)
+		}
+		if tt.status == http.StatusOK && w.Body.String() != tt.body {
+			t.Fatalf("%s %s: unexpected body: %q", tt.method, tt.path, w.Body.String())
+		}
+	}
+}
+
+func TestHandler_EmptyBody(t *testing.T) {
+	cf := &filterweb.ConfigFile{Routes: []filterweb.ConfigSchema{{Path: "/csv", Filters: []filterweb.Config{
+		{Name: "constant", Params: map[string]any{"ContentType": "text/csv", "Data": "a\n"}},
+	}}}}
+	compiled, err := cf.Compile()
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	s := &WebServer{}
+	w := httptest.NewRecorder()
+	s.handler(compiled.Routes[0], compiled.Pipelines).ServeHTTP(w, httptest.NewRequest("GET", "/csv", nil))
+	if w.Code != http.StatusOK || w.Body.Len() != 0 {
+		t.Fatalf("unexpected response: %d %q", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/csv" {
+		t.Fatalf("unexpected content type: %s", ct)
+	}
+}
+
+// serveState sets routes to the webserver
+func serveState(t *testing.T, s *WebServer, routes ...filterweb.ConfigSchema) {
+	t.Helper()
+	compiled := compile(t, routes...)
+	mux, err := s.buildMux(compiled)
+	if err != nil {
+		t.Fatalf("buildMux failed: %v", err)
+	}
+	s.state.Store(&serverState{configData: compiled, mux: mux})
+}
+
+func TestServeHTTP_MaxBodySize(t *testing.T) {
+	s := &WebServer{MaxBodySize: 10}
+	serveState(t, s, filterweb.ConfigSchema{Path: "/post", Method: "POST"})
+	for body, status := range map[string]int{
+		"small":                   http.StatusOK,
+		strings.Repeat("x", 100):  http.StatusRequestEntityTooLarge,
+		strings.Repeat("x", 10):   http.StatusOK,
+		strings.Repeat("x", 10+1): http.StatusRequestEntityTooLarge,
+	} {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest("POST", "/post", strings.NewReader(body)))
+		if w.Code != status {
+			t.Fatalf("body of %d bytes: unexpected status: %d", len(body), w.Code)
+		}
+	}
+}
+
+func TestServer_Options(t *testing.T) {
+	s := &WebServer{
+		Listen: "127.0.0.1:0", ReadTimeout: 100 * time.Millisecond, WriteTimeout: 2 * time.Second,
+		IdleTimeout: 3 * time.Second, MaxHeaderBytes: 1024, ShutdownTimeout: time.Second,
+	}
+	srv := s.server(nil)
+	if srv.Handler != s || srv.ReadTimeout != s.ReadTimeout || srv.WriteTimeout != s.WriteTimeout ||
+		srv.IdleTimeout != s.IdleTimeout || srv.MaxHeaderBytes != s.MaxHeaderBytes {
+		t.Fatalf("options are not applied: %+v", srv)
+	}
+	serveState(t, s, filterweb.ConfigSchema{Path: "/"})
+	ln, err := net.Listen("tcp", s.Listen)
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.serve(ctx, ln, srv) }()
+	defer func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("serve failed: %v", err)
+		}
+	}()
+	url := "http://" + ln.Addr().String() + "/"
+
+	// header is limited by MaxHeaderBytes (with some slack of net/http)
+	req, _ := http.NewRequest("GET", url, nil)
+	req.Header.Set("X-Large", strings.Repeat("x", 16*1024))
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusRequestHeaderFieldsTooLarge {
+		t.Fatalf("unexpected status: %d", res.StatusCode)
+	}
+
+	// incomplete request is closed by ReadTimeout
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer conn.Close()
+	io.WriteString(conn, "GET / HTTP/1.1\r\n")
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := conn.Read(make([]byte, 1024)); err != io.EOF {
+		t.Fatalf("connection is not closed by read timeout: %v", err)
+	}
+}
+
+func TestServe_Shutdown(t *testing.T) {
+	s := &WebServer{ShutdownTimeout: 5 * time.Second}
+	started := make(chan struct{})
+	srv := s.server(nil)
+	srv.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		io.WriteString(w, "done")
+	})
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), shutdownSignals...)
+	defer stop()
+	done := make(chan error)
+	go func() { done <- s.serve(ctx, ln, srv) }()
+
+	result := make(chan string)
+	go func() {
+		res, err := http.Get("http://" + ln.Addr().String() + "/")
+		if err != nil {
+			t.Errorf("request failed: %v", err)
+			result <- ""
+			return
+		}
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		result <- string(body)
+	}()
+	<-started
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("kill failed: %v", err)
+	}
+	// in-flight request is drained
+	if body := <-result; body != "done" {
+		t.Fatalf("unexpected body: %q", body)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("serve failed: %v", err)
+	}
+	if _, err := net.Dial("tcp", ln.Addr().String()); err == nil {
+		t.Fatalf("listener is not closed")
+	}
+}
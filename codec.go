@@ -0,0 +1,162 @@
+package filterweb
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"mime"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/joho/godotenv"
+)
+
+// Codec converts between encoded bytes and data of content types
+type Codec interface {
+	ContentTypes() []string // MIME types, the first one is canonical
+	Extensions() []string   // file extensions without dot
+	Decode(data []byte) (any, error)
+	Encode(data any) ([]byte, error)
+}
+
+// ConfigurableCodec is a Codec which has options (e.g. delimiter of CSV)
+type ConfigurableCodec interface {
+	Codec
+	WithOptions(options map[string]any) (Codec, error)
+}
+
+// StreamCodec is a Codec which decodes from a reader without reading whole input first
+type StreamCodec interface {
+	Codec
+	DecodeReader(r io.Reader) (any, error)
+}
+
+var codecs map[string]Codec = make(map[string]Codec)
+var codecExtensions map[string]Codec = make(map[string]Codec)
+
+// RegisterCodec registers codec for its content types and extensions, replaces existing ones
+func RegisterCodec(c Codec) {
+	for _, ct := range c.ContentTypes() {
+		codecs[strings.ToLower(ct)] = c
+	}
+	for _, ext := range c.Extensions() {
+		codecExtensions[strings.ToLower(strings.TrimPrefix(ext, "."))] = c
+	}
+}
+
+// GetCodec returns codec of content type, parameters such as charset are ignored
+func GetCodec(contentType string) (Codec, error) {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		contentType = mediaType
+	}
+	c, ok := codecs[strings.ToLower(contentType)]
+	if !ok {
+		return nil, ErrCodecNotFound
+	}
+	return c, nil
+}
+
+// GetCodecOptions returns codec of content type configured with options
+func GetCodecOptions(contentType string, options map[string]any) (Codec, error) {
+	c, err := GetCodec(contentType)
+	if err != nil || len(options) == 0 {
+		return c, err
+	}
+	cc, ok := c.(ConfigurableCodec)
+	if !ok {
+		slog.Error("codec has no options", "contenttype", contentType, "options", options)
+		return nil, ErrInvalidParams
+	}
+	return cc.WithOptions(options)
+}
+
+// getStreamCodec returns StreamCodec of content type, nil if not supported
+func getStreamCodec(contentType string) StreamCodec {
+	c, err := GetCodec(contentType)
+	if err != nil {
+		return nil
+	}
+	sc, _ := c.(StreamCodec)
+	return sc
+}
+
+// GetCodecByExtension returns codec of file extension such as ".json" or "yaml"
+func GetCodecByExtension(ext string) (Codec, error) {
+	c, ok := codecExtensions[strings.ToLower(strings.TrimPrefix(ext, "."))]
+	if !ok {
+		return nil, ErrCodecNotFound
+	}
+	return c, nil
+}
+
+// ListCodecs returns registered content types
+func ListCodecs() []string {
+	var names []string
+	for name := range codecs {
+		names = append(names, name)
+	}
+	return names
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentTypes() []string { return []string{"application/json"} }
+func (jsonCodec) Extensions() []string   { return []string{"json"} }
+
+func (jsonCodec) Decode(data []byte) (res any, err error) {
+	err = json.Unmarshal(data, &res)
+	return
+}
+
+func (jsonCodec) Encode(data any) ([]byte, error) {
+	return json.Marshal(data)
+}
+
+type yamlCodec struct{}
+
+func (yamlCodec) ContentTypes() []string { return []string{"application/yaml", "text/yaml"} }
+func (yamlCodec) Extensions() []string   { return []string{"yaml", "yml"} }
+
+func (yamlCodec) Decode(data []byte) (res any, err error) {
+	err = yaml.Unmarshal(data, &res)
+	return
+}
+
+func (yamlCodec) Encode(data any) ([]byte, error) {
+	return yaml.Marshal(data)
+}
+
+type dotenvCodec struct{}
+
+func (dotenvCodec) ContentTypes() []string { return []string{"text/dotenv"} } // custom
+func (dotenvCodec) Extensions() []string   { return []string{"env"} }
+
+func (dotenvCodec) Decode(data []byte) (any, error) {
+	smap, err := godotenv.Parse(bytes.NewReader(data))
+	if err != nil {
+		slog.Error("dotenv parse error", "error", err)
+		return nil, err
+	}
+	return smap, nil
+}
+
+func (dotenvCodec) Encode(data any) ([]byte, error) {
+	smap, ok := data.(map[string]string)
+	if !ok {
+		slog.Error("cannot encode to dotenv", "data", data)
+		return nil, ErrEncode
+	}
+	buf, err := godotenv.Marshal(smap)
+	if err != nil {
+		slog.Error("dotenv encode error", "error", err, "data", smap)
+		return nil, err
+	}
+	return []byte(buf), nil
+}
+
+func init() {
+	RegisterCodec(jsonCodec{})
+	RegisterCodec(yamlCodec{})
+	RegisterCodec(dotenvCodec{})
+}
@@ -0,0 +1,63 @@
+package filterweb
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// upperCodec is a codec for tests
+type upperCodec struct{}
+
+func (upperCodec) ContentTypes() []string { return []string{"text/x-upper", "application/x-upper"} }
+func (upperCodec) Extensions() []string   { return []string{".upper"} }
+
+func (upperCodec) Decode(data []byte) (any, error) {
+	return strings.ToLower(string(data)), nil
+}
+
+func (upperCodec) Encode(data any) ([]byte, error) {
+	return []byte(strings.ToUpper(fmt.Sprint(data))), nil
+}
+
+func TestRegisterCodec(t *testing.T) {
+	RegisterCodec(upperCodec{})
+	res, err := DecodeContentType("application/x-upper", []byte("HELLO"))
+	if err != nil || res != "hello" {
+		t.Fatalf("unexpected result: %v %v", res, err)
+	}
+	buf, err := EncodeContentType("text/x-upper; charset=utf-8", "hello")
+	if err != nil || string(buf) != "HELLO" {
+		t.Fatalf("unexpected result: %s %v", buf, err)
+	}
+	if d := (Data{ContentType: "text/x-upper", Data: []string{"a", "b"}}).String(); d != "[A B]" {
+		t.Fatalf("unexpected string: %s", d)
+	}
+	if c, err := GetCodecByExtension("upper"); err != nil || c.ContentTypes()[0] != "text/x-upper" {
+		t.Fatalf("unexpected codec: %v %v", c, err)
+	}
+	if !slices.Contains(ListCodecs(), "application/x-upper") {
+		t.Fatalf("codec is not listed: %v", ListCodecs())
+	}
+}
+
+func TestGetCodec(t *testing.T) {
+	c, err := GetCodec("Application/JSON; charset=utf-8")
+	if err != nil || c.ContentTypes()[0] != "application/json" {
+		t.Fatalf("unexpected codec: %v %v", c, err)
+	}
+	if c, err = GetCodecByExtension(".yml"); err != nil || c.ContentTypes()[0] != "application/yaml" {
+		t.Fatalf("unexpected codec: %v %v", c, err)
+	}
+	if _, err = GetCodec("application/x-unknown"); err != ErrCodecNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestDecodeContentType_Unknown(t *testing.T) {
+	res, err := DecodeContentType("application/octet-stream", []byte("raw"))
+	if err != nil || string(res.([]byte)) != "raw" {
+		t.Fatalf("unexpected result: %v %v", res, err)
+	}
+}
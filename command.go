@@ -2,13 +2,12 @@ package filterweb
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"os/exec"
-
-	"github.com/go-viper/mapstructure/v2"
 )
 
 // single command
@@ -34,11 +33,15 @@ func (cc *CommandConfig) Accepts() []string {
 	return []string{}
 }
 
+func (cc *CommandConfig) OutputContentType(input string) string {
+	return cc.ContentType
+}
+
 func (cc *CommandConfig) Prep(config Config, data Data) error {
 	// defaults
 	cc.KeepEnvs = false
 	cc.ContentType = "text/plain"
-	err := mapstructure.Decode(config.Params, cc)
+	err := decodeParams(config.Params, cc)
 	if err != nil {
 		return err
 	}
@@ -51,8 +54,12 @@ func (cc *CommandConfig) Prep(config Config, data Data) error {
 }
 
 func (cc *CommandConfig) Process(data Data) (Data, error) {
+	return cc.ProcessContext(context.Background(), data)
+}
+
+func (cc *CommandConfig) ProcessContext(ctx context.Context, data Data) (Data, error) {
 	res := Data{ContentType: cc.ContentType}
-	cmd := exec.Command(cc.Args[0], cc.Args[1:]...)
+	cmd := exec.CommandContext(ctx, cc.Args[0], cc.Args[1:]...)
 	if cc.Dir != "" {
 		cmd.Dir = cc.Dir
 	}
@@ -66,25 +73,22 @@ func (cc *CommandConfig) Process(data Data) (Data, error) {
 	if err != nil {
 		slog.Error("stdinpipe", "error", err)
 	}
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		slog.Error("stdoutpipe", "error", err)
-	}
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		slog.Error("stderrpipe", "error", err)
-	}
 	stdoutbuf := bytes.Buffer{}
 	stderrbuf := bytes.Buffer{}
+	cmd.Stderr = &stderrbuf
+	sc := getStreamCodec(cc.ContentType)
+	var stdout io.ReadCloser
+	if sc != nil {
+		// decode while the command is running
+		if stdout, err = cmd.StdoutPipe(); err != nil {
+			slog.Error("stdoutpipe", "error", err)
+			return res, err
+		}
+	} else {
+		cmd.Stdout = &stdoutbuf
+	}
 	go func() {
-		io.Copy(&stdoutbuf, stdout)
-		stdout.Close()
-	}()
-	go func() {
-		io.Copy(&stderrbuf, stderr)
-		stderr.Close()
-	}()
-	go func() {
+		defer stdin.Close()
 		if cc.InputContentType != "" {
 			bbuf, err := EncodeContentType(cc.InputContentType, data.Data)
 			if err != nil {
@@ -103,6 +107,21 @@ func (cc *CommandConfig) Process(data Data) (Data, error) {
 			}
 		}
 	}()
+	if sc != nil {
+		if err = cmd.Start(); err != nil {
+			slog.Error("command failed", "error", err)
+			return res, err
+		}
+		var decerr error
+		res.Data, decerr = sc.DecodeReader(stdout)
+		// read rest of output not to block the command
+		io.Copy(io.Discard, stdout)
+		if err = cmd.Wait(); err != nil {
+			slog.Error("command failed", "error", err, "stderr", stderrbuf.String())
+			return res, err
+		}
+		return res, decerr
+	}
 	err = cmd.Run()
 	if err != nil {
 		slog.Error("command failed", "error", err, "stdout", stdoutbuf.String(), "stderr", stderrbuf.String())
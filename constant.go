@@ -2,14 +2,14 @@ package filterweb
 
 import (
 	"log/slog"
-
-	"github.com/go-viper/mapstructure/v2"
 )
 
 type ConstantConfig struct {
 	Filter
-	ContentType string // target content type
-	Data        any    // constant data
+	ContentType string         // target content type
+	Data        any            // constant data
+	Options     map[string]any // codec options (e.g. delimiter of text/csv)
+	codec       Codec
 }
 
 func (hc *ConstantConfig) New() Filter {
@@ -24,10 +24,14 @@ func (hc *ConstantConfig) Accepts() []string {
 	return []string{}
 }
 
+func (hc *ConstantConfig) OutputContentType(input string) string {
+	return hc.ContentType
+}
+
 func (hc *ConstantConfig) Prep(config Config, data Data) error {
 	// defaults
 	hc.ContentType = "text/plain"
-	err := mapstructure.Decode(config.Params, hc)
+	err := decodeParams(config.Params, hc)
 	if err != nil {
 		return err
 	}
@@ -36,17 +40,30 @@ func (hc *ConstantConfig) Prep(config Config, data Data) error {
 		slog.Error("constant filter requires 'data' parameter")
 		return ErrMissingParams
 	}
+	if len(hc.Options) != 0 {
+		if hc.codec, err = GetCodecOptions(hc.ContentType, hc.Options); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// decode decodes constant data with the codec
+func (hc *ConstantConfig) decode(data []byte) (any, error) {
+	if hc.codec != nil {
+		return hc.codec.Decode(data)
+	}
+	return DecodeContentType(hc.ContentType, data)
+}
+
 func (hc *ConstantConfig) Process(data Data) (Data, error) {
 	res := Data{}
 	res.ContentType = hc.ContentType
 	var err error
 	if strdata, ok := hc.Data.(string); ok {
-		res.Data, err = DecodeContentType(hc.ContentType, []byte(strdata))
+		res.Data, err = hc.decode([]byte(strdata))
 	} else if bindata, ok := hc.Data.([]byte); ok {
-		res.Data, err = DecodeContentType(hc.ContentType, bindata)
+		res.Data, err = hc.decode(bindata)
 	} else {
 		res.Data = hc.Data
 	}
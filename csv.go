@@ -0,0 +1,247 @@
+package filterweb
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// csvCodec is a codec of CSV and TSV.
+//
+// with header, rows are decoded to maps keyed by column names. without header and
+// columns, rows are decoded to arrays.
+type csvCodec struct {
+	Delimiter  string   // field delimiter, "," for CSV and "\t" for TSV
+	NoHeader   bool     // no header row
+	Columns    []string // column names and order, default is header row (decode) or sorted keys (encode)
+	Infer      bool     // decode numbers, true/false and empty values as number, bool and null
+	Comment    string   // lines beginning with the character are ignored
+	LazyQuotes bool     // allow quotes in unquoted field
+	types      []string
+	extensions []string
+}
+
+var csvDefault = csvCodec{Delimiter: ",", types: []string{"text/csv"}, extensions: []string{"csv"}}
+var tsvDefault = csvCodec{Delimiter: "\t", types: []string{"text/tab-separated-values"}, extensions: []string{"tsv"}}
+
+func (c csvCodec) ContentTypes() []string { return c.types }
+func (c csvCodec) Extensions() []string   { return c.extensions }
+
+// toRune converts option of single character, 0 for empty
+func toRune(name, s string) (rune, error) {
+	if s == "" {
+		return 0, nil
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	if size != len(s) {
+		slog.Error("csv option requires single character", "option", name, "value", s)
+		return 0, ErrInvalidParams
+	}
+	return r, nil
+}
+
+func (c csvCodec) WithOptions(options map[string]any) (Codec, error) {
+	res := c
+	if err := decodeOptions(options, &res); err != nil {
+		slog.Error("csv options decode", "options", options, "error", err)
+		return nil, fmt.Errorf("%w: %v", ErrInvalidParams, err)
+	}
+	if _, _, err := res.runes(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// runes returns delimiter and comment character
+func (c csvCodec) runes() (comma, comment rune, err error) {
+	if comma, err = toRune("delimiter", c.Delimiter); err != nil {
+		return
+	}
+	if comma == 0 {
+		comma = ','
+	}
+	comment, err = toRune("comment", c.Comment)
+	return
+}
+
+// infer converts string to number, bool or nil. numbers with leading zeros (e.g. zip codes)
+// and integers out of range of int are kept as strings.
+func infer(s string) any {
+	switch s {
+	case "":
+		return nil
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	digits := strings.TrimLeft(s, "+-")
+	if len(digits) > 1 && digits[0] == '0' && digits[1] >= '0' && digits[1] <= '9' {
+		return s
+	}
+	i, err := strconv.Atoi(s)
+	if err == nil {
+		return i
+	} else if errors.Is(err, strconv.ErrRange) {
+		return s
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+		return f
+	}
+	return s
+}
+
+func (c csvCodec) Decode(data []byte) (any, error) {
+	comma, comment, err := c.runes()
+	if err != nil {
+		return nil, err
+	}
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = comma
+	reader.Comment = comment
+	reader.LazyQuotes = c.LazyQuotes
+	reader.FieldsPerRecord = -1
+	columns := c.Columns
+	if !c.NoHeader {
+		hdr, err := reader.Read()
+		if err == io.EOF {
+			return []any{}, nil
+		} else if err != nil {
+			slog.Error("csv read error", "error", err)
+			return nil, err
+		}
+		if columns == nil {
+			columns = hdr
+		}
+	}
+	res := []any{}
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			slog.Error("csv read error", "error", err)
+			return nil, err
+		}
+		values := make([]any, len(row))
+		for i, v := range row {
+			if c.Infer {
+				values[i] = infer(v)
+			} else {
+				values[i] = v
+			}
+		}
+		if columns == nil {
+			res = append(res, values)
+			continue
+		}
+		if len(values) > len(columns) {
+			line, _ := reader.FieldPos(0)
+			return nil, fmt.Errorf("%w: line %d has %d fields, more than %d columns", ErrDecode, line, len(values), len(columns))
+		}
+		m := make(map[string]any)
+		for i, col := range columns {
+			if i < len(values) {
+				m[col] = values[i]
+			} else {
+				m[col] = nil
+			}
+		}
+		res = append(res, m)
+	}
+	return res, nil
+}
+
+// csvValue formats a field value
+func csvValue(v any) (string, error) {
+	switch val := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return val, nil
+	case []byte:
+		return string(val), nil
+	case map[string]any, []any:
+		buf, err := json.Marshal(val)
+		return string(buf), err
+	}
+	return fmt.Sprint(v), nil
+}
+
+func (c csvCodec) Encode(data any) ([]byte, error) {
+	comma, _, err := c.runes()
+	if err != nil {
+		return nil, err
+	}
+	rows, ok := normalize(data).([]any)
+	if !ok {
+		slog.Error("csv requires array of rows", "data", data)
+		return nil, fmt.Errorf("%w: data is not an array", ErrEncode)
+	}
+	columns := c.Columns
+	if columns == nil {
+		// union of keys, sorted for stable output
+		keys := map[string]bool{}
+		for _, row := range rows {
+			if m, ok := normalize(row).(map[string]any); ok {
+				for k := range m {
+					keys[k] = true
+				}
+			}
+		}
+		columns = slices.Sorted(maps.Keys(keys))
+	}
+	buf := &bytes.Buffer{}
+	writer := csv.NewWriter(buf)
+	writer.Comma = comma
+	if !c.NoHeader && len(columns) != 0 {
+		if err = writer.Write(columns); err != nil {
+			slog.Error("csv write error", "error", err)
+			return nil, err
+		}
+	}
+	for i, row := range rows {
+		var fields []any
+		switch val := normalize(row).(type) {
+		case map[string]any:
+			for _, col := range columns {
+				fields = append(fields, val[col])
+			}
+		case []any:
+			fields = val
+		default:
+			slog.Error("csv row is not an object or array", "index", i, "row", row)
+			return nil, fmt.Errorf("%w: row %d is not an object or array", ErrEncode, i)
+		}
+		record := make([]string, len(fields))
+		for j, f := range fields {
+			if record[j], err = csvValue(f); err != nil {
+				return nil, err
+			}
+		}
+		if err = writer.Write(record); err != nil {
+			slog.Error("csv write error", "error", err)
+			return nil, err
+		}
+	}
+	writer.Flush()
+	if err = writer.Error(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrEncode, err)
+	}
+	return buf.Bytes(), nil
+}
+
+func init() {
+	RegisterCodec(csvDefault)
+	RegisterCodec(tsvDefault)
+}
@@ -0,0 +1,191 @@
+package filterweb
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCSV_Decode(t *testing.T) {
+	res, err := DecodeContentType("text/csv", []byte("name,age\nAlice,30\nBob,\n"))
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	expected := []any{
+		map[string]any{"name": "Alice", "age": "30"},
+		map[string]any{"name": "Bob", "age": ""},
+	}
+	if !reflect.DeepEqual(res, expected) {
+		t.Fatalf("unexpected result: %#v", res)
+	}
+}
+
+func TestCSV_Decode_Error(t *testing.T) {
+	if _, err := DecodeContentType("text/csv", []byte("a,b\n1,\"2\n")); err == nil {
+		t.Fatalf("expected error")
+	}
+	if _, err := DecodeContentType("text/csv", []byte("a,b\n1,2,3\n")); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestCSV_Decode_Options(t *testing.T) {
+	codec, err := GetCodecOptions("text/tab-separated-values", map[string]any{
+		"NoHeader": true, "Columns": []string{"name", "age", "admin", "note"},
+		"Infer": true, "Comment": "#", "LazyQuotes": true,
+	})
+	if err != nil {
+		t.Fatalf("GetCodecOptions failed: %v", err)
+	}
+	res, err := codec.Decode([]byte("# comment\nAlice\t30\ttrue\ta \"quoted\" note\nBob\t1.5\tfalse\t\n"))
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	expected := []any{
+		map[string]any{"name": "Alice", "age": 30, "admin": true, "note": `a "quoted" note`},
+		map[string]any{"name": "Bob", "age": 1.5, "admin": false, "note": nil},
+	}
+	if !reflect.DeepEqual(res, expected) {
+		t.Fatalf("unexpected result: %#v", res)
+	}
+}
+
+func TestCSV_Decode_Arrays(t *testing.T) {
+	codec, err := GetCodecOptions("text/csv", map[string]any{"NoHeader": true, "Delimiter": ";"})
+	if err != nil {
+		t.Fatalf("GetCodecOptions failed: %v", err)
+	}
+	res, err := codec.Decode([]byte("a;b\nc;d\n"))
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	expected := []any{[]any{"a", "b"}, []any{"c", "d"}}
+	if !reflect.DeepEqual(res, expected) {
+		t.Fatalf("unexpected result: %#v", res)
+	}
+}
+
+func TestCSV_Decode_InferNaN(t *testing.T) {
+	codec, err := GetCodecOptions("text/csv", map[string]any{"Infer": true})
+	if err != nil {
+		t.Fatalf("GetCodecOptions failed: %v", err)
+	}
+	res, err := codec.Decode([]byte("a,b,c,d\nnan,Inf,-infinity,1e3\n"))
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	expected := []any{map[string]any{"a": "nan", "b": "Inf", "c": "-infinity", "d": 1000.0}}
+	if !reflect.DeepEqual(res, expected) {
+		t.Fatalf("unexpected result: %#v", res)
+	}
+	if _, err := EncodeContentType("application/json", res); err != nil {
+		t.Fatalf("encode to json failed: %v", err)
+	}
+}
+
+func TestInfer(t *testing.T) {
+	tests := map[string]any{
+		"0": 0, "-12": -12, "0.5": 0.5, "-0.5": -0.5, "1e3": 1000.0,
+		"01234": "01234", "-007": "-007", "00": "00",
+		"12345678901234567890": "12345678901234567890",
+		"nan":                  "nan", "true": true, "": nil,
+	}
+	for in, expected := range tests {
+		if v := infer(in); v != expected {
+			t.Fatalf("unexpected result of %q: %#v", in, v)
+		}
+	}
+}
+
+func TestCSV_Options_Invalid(t *testing.T) {
+	if _, err := GetCodecOptions("text/csv", map[string]any{"Delimiter": "::"}); err != ErrInvalidParams {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := GetCodecOptions("application/json", map[string]any{"Delimiter": ";"}); err != ErrInvalidParams {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := GetCodecOptions("text/csv", map[string]any{"no_header": true}); !errors.Is(err, ErrInvalidParams) {
+		t.Fatalf("unknown option is accepted: %v", err)
+	}
+	ec := &EncodeConfig{}
+	cfg := Config{Params: map[string]any{"ContentType": "text/csv", "Options": map[string]any{"delimter": ";"}}}
+	if err := ec.Prep(cfg, Data{}); !errors.Is(err, ErrInvalidParams) {
+		t.Fatalf("unknown option is accepted by Prep: %v", err)
+	}
+}
+
+func TestCSV_Encode(t *testing.T) {
+	in := []any{
+		map[string]any{"name": "Alice", "age": 30.0, "tags": []any{"a"}},
+		map[string]any{"name": "Bob", "admin": true},
+	}
+	buf, err := EncodeContentType("text/csv", in)
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	expected := "admin,age,name,tags\n,30,Alice,\"[\"\"a\"\"]\"\ntrue,,Bob,\n"
+	if string(buf) != expected {
+		t.Fatalf("unexpected csv: %q", buf)
+	}
+}
+
+func TestCSV_Encode_Empty(t *testing.T) {
+	buf, err := EncodeContentType("text/csv", []any{})
+	if err != nil || len(buf) != 0 {
+		t.Fatalf("unexpected result: %q %v", buf, err)
+	}
+}
+
+func TestEncode_Process_TSVOptions(t *testing.T) {
+	ec := &EncodeConfig{}
+	cfg := Config{Params: map[string]any{
+		"ContentType": "text/tab-separated-values",
+		"Options":     map[string]any{"Columns": []string{"name", "age"}},
+	}}
+	if err := ec.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	out, err := ec.Process(Data{Data: []any{
+		map[string]any{"age": 30, "name": "Alice", "ignored": 1},
+		[]any{"Bob", 40},
+	}})
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if s := string(out.Data.([]byte)); s != "name\tage\nAlice\t30\nBob\t40\n" {
+		t.Fatalf("unexpected tsv: %q", s)
+	}
+}
+
+func TestConstant_Process_CSVOptions(t *testing.T) {
+	cc := &ConstantConfig{}
+	cfg := Config{Params: map[string]any{
+		"ContentType": "text/csv",
+		"Data":        "id,ok\n1,true\n",
+		"Options":     map[string]any{"Infer": true},
+	}}
+	if err := cc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	out, err := cc.Process(Data{})
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	expected := []any{map[string]any{"id": 1, "ok": true}}
+	if !reflect.DeepEqual(out.Data, expected) {
+		t.Fatalf("unexpected data: %#v", out.Data)
+	}
+}
+
+func TestCSV_Jq(t *testing.T) {
+	out, err := ProcessFilters([]Config{
+		{Name: "constant", Params: map[string]any{"ContentType": "text/csv", "Data": "n\n1\n2\n", "Options": map[string]any{"Infer": true}}},
+		{Name: "jq", Params: map[string]any{"Expression": "map(.n) | add"}},
+	})
+	if err != nil {
+		t.Fatalf("ProcessFilters failed: %v", err)
+	}
+	if out.String() != "[3]" {
+		t.Fatalf("unexpected output: %v", out)
+	}
+}
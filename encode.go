@@ -2,13 +2,13 @@ package filterweb
 
 import (
 	"log/slog"
-
-	"github.com/go-viper/mapstructure/v2"
 )
 
 type EncodeConfig struct {
 	Filter
-	ContentType string // target content type
+	ContentType string         // target content type
+	Options     map[string]any // codec options (e.g. delimiter of text/csv)
+	codec       Codec
 }
 
 func (ec *EncodeConfig) New() Filter {
@@ -23,8 +23,12 @@ func (ec *EncodeConfig) Accepts() []string {
 	return []string{}
 }
 
+func (ec *EncodeConfig) OutputContentType(input string) string {
+	return ec.ContentType
+}
+
 func (ec *EncodeConfig) Prep(config Config, data Data) error {
-	err := mapstructure.Decode(config.Params, ec)
+	err := decodeParams(config.Params, ec)
 	if err != nil {
 		return err
 	}
@@ -33,6 +37,11 @@ func (ec *EncodeConfig) Prep(config Config, data Data) error {
 		slog.Error("encode filter requires 'content_type' parameter")
 		return ErrMissingParams
 	}
+	if len(ec.Options) != 0 {
+		if ec.codec, err = GetCodecOptions(ec.ContentType, ec.Options); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -40,7 +49,11 @@ func (ec *EncodeConfig) Process(data Data) (Data, error) {
 	res := Data{}
 	var err error
 	res.ContentType = ec.ContentType
-	res.Data, err = EncodeContentType(ec.ContentType, data.Data)
+	if ec.codec != nil {
+		res.Data, err = ec.codec.Encode(data.Data)
+	} else {
+		res.Data, err = EncodeContentType(ec.ContentType, data.Data)
+	}
 	return res, err
 }
 
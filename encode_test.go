@@ -3,6 +3,8 @@ package filterweb
 import (
 	"encoding/csv"
 	"encoding/json"
+	"errors"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -125,3 +127,147 @@ func TestEncode_Process_CSV(t *testing.T) {
 		t.Fatalf("unexpected row1: %v", row1)
 	}
 }
+
+func TestEncode_Process_TOML(t *testing.T) {
+	ec := &EncodeConfig{}
+	cfg := Config{Params: map[string]any{"ContentType": "application/toml"}}
+	if err := ec.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	in := map[string]any{
+		"title":  "example",
+		"server": map[string]any{"port": 8080, "hosts": []any{"a", "b"}},
+	}
+	out, err := ec.Process(Data{Data: in})
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	back, err := DecodeContentType("application/toml", out.Data.([]byte))
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if !reflect.DeepEqual(back, in) {
+		t.Fatalf("round trip failed: %#v", back)
+	}
+}
+
+func TestEncode_TOML_FromJSON(t *testing.T) {
+	in, err := DecodeContentType("application/json", []byte(`{"server": {"port": 8080, "ratio": 0.5, "ids": [1, 2]}}`))
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	out, err := EncodeContentType("application/toml", in)
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	if s := string(out); !strings.Contains(s, "port = 8080\n") || !strings.Contains(s, "ratio = 0.5\n") || !strings.Contains(s, "ids = [1, 2]\n") {
+		t.Fatalf("unexpected toml: %s", s)
+	}
+	back, err := DecodeContentType("application/toml", out)
+	if err != nil {
+		t.Fatalf("decode toml failed: %v", err)
+	}
+	if port := back.(map[string]any)["server"].(map[string]any)["port"]; port != 8080 {
+		t.Fatalf("unexpected port: %#v", port)
+	}
+}
+
+func TestDecode_TOML(t *testing.T) {
+	src := `
+name = "app"
+debug = true
+ratio = 0.5
+date = 1979-05-27
+at = 1979-05-27T07:32:00Z
+
+[[users]]
+name = "Alice"
+age = 30
+`
+	res, err := DecodeContentType("application/toml", []byte(src))
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	expected := map[string]any{
+		"name": "app", "debug": true, "ratio": 0.5,
+		"date": "1979-05-27", "at": "1979-05-27T07:32:00Z",
+		"users": []any{map[string]any{"name": "Alice", "age": 30}},
+	}
+	if !reflect.DeepEqual(res, expected) {
+		t.Fatalf("unexpected result: %#v", res)
+	}
+	buf, err := EncodeContentType("application/toml", res)
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	back, err := DecodeContentType("application/toml", buf)
+	if err != nil {
+		t.Fatalf("decode failed: %v\n%s", err, buf)
+	}
+	if !reflect.DeepEqual(back, expected) {
+		t.Fatalf("round trip failed: %#v", back)
+	}
+}
+
+func TestEncode_Process_INI(t *testing.T) {
+	ec := &EncodeConfig{}
+	cfg := Config{Params: map[string]any{"ContentType": "text/x-ini"}}
+	if err := ec.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	in := map[string]any{
+		"name":     "app",
+		"database": map[string]any{"host": "localhost", "port": "5432", "password": " p;#\"w "},
+		"log":      map[string]any{"level": "info"},
+	}
+	out, err := ec.Process(Data{Data: in})
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	s := string(out.Data.([]byte))
+	if !strings.HasPrefix(s, "name = app\n\n[database]\nhost = localhost\n") {
+		t.Fatalf("unexpected ini: %q", s)
+	}
+	back, err := DecodeContentType("text/x-ini", out.Data.([]byte))
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if !reflect.DeepEqual(back, in) {
+		t.Fatalf("round trip failed: %#v", back)
+	}
+}
+
+func TestDecode_INI(t *testing.T) {
+	src := `; comment
+global = 1
+[server]
+# comment
+host: example.com
+name = "quoted value"
+[server]
+port = 80
+`
+	res, err := DecodeContentType("text/ini", []byte(src))
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	expected := map[string]any{
+		"global": "1",
+		"server": map[string]any{"host": "example.com", "name": "quoted value", "port": "80"},
+	}
+	if !reflect.DeepEqual(res, expected) {
+		t.Fatalf("unexpected result: %#v", res)
+	}
+	if _, err = DecodeContentType("text/x-ini", []byte("[broken\n")); err == nil {
+		t.Fatalf("expected error")
+	}
+	if _, err = DecodeContentType("text/x-ini", []byte("a=1\n[a]\nb=2\n")); !errors.Is(err, ErrDecode) {
+		t.Fatalf("section conflicts with key: %v", err)
+	}
+	if res, err = DecodeContentType("text/x-ini", []byte("[a]\nb=1\n[a]\nc=2\n")); err != nil || !reflect.DeepEqual(res, map[string]any{"a": map[string]any{"b": "1", "c": "2"}}) {
+		t.Fatalf("repeated section is not merged: %v %v", res, err)
+	}
+	if _, err = EncodeContentType("text/x-ini", map[string]any{"a": map[string]any{"b": map[string]any{}}}); err == nil {
+		t.Fatalf("expected error")
+	}
+}
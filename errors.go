@@ -1,6 +1,10 @@
 package filterweb
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrFilterNotFound      = errors.New("filter not found")
@@ -8,7 +12,95 @@ var (
 	ErrHTTPRequestFailed   = errors.New("http request failed")
 	ErrHTTPStatusNotOK     = errors.New("http status not ok")
 	ErrMissingParams       = errors.New("missing parameters")
+	ErrInvalidParams       = errors.New("invalid parameters")
 	ErrReadTemplate        = errors.New("failed to read template")
 	ErrEncode              = errors.New("encode error")
 	ErrDecode              = errors.New("decode error")
+	ErrInvalidData         = errors.New("invalid data")
+	ErrInvalidRoute        = errors.New("invalid route")
+	ErrTLSConfig           = errors.New("invalid tls config")
+	ErrPipelineNotFound    = errors.New("pipeline not found")
+	ErrCallDepth           = errors.New("pipeline call too deep")
+	ErrIncludeCycle        = errors.New("include cycle")
+	ErrCodecNotFound       = errors.New("codec not found")
+	ErrUnknownVariable     = errors.New("unknown variable")
 )
+
+// FilterError is an error of the filter in the filters
+type FilterError struct {
+	Filter string // filter name
+	Index  int    // index in the filters
+	Err    error
+}
+
+func (e *FilterError) Error() string {
+	return fmt.Sprintf("filter %s[%d]: %s", e.Filter, e.Index, e.Err)
+}
+
+func (e *FilterError) Unwrap() error {
+	return e.Err
+}
+
+// StatusError is an error with HTTP status code of upstream
+type StatusError struct {
+	StatusCode int
+	Err        error
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: %d", e.Err, e.StatusCode)
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+var errorKinds = []struct {
+	err  error
+	kind string
+}{
+	{ErrFilterNotFound, "ErrFilterNotFound"},
+	{ErrContentTypeMismatch, "ErrContentTypeMismatch"},
+	{ErrHTTPRequestFailed, "ErrHTTPRequestFailed"},
+	{ErrHTTPStatusNotOK, "ErrHTTPStatusNotOK"},
+	{ErrMissingParams, "ErrMissingParams"},
+	{ErrInvalidParams, "ErrInvalidParams"},
+	{ErrReadTemplate, "ErrReadTemplate"},
+	{ErrEncode, "ErrEncode"},
+	{ErrDecode, "ErrDecode"},
+	{ErrInvalidData, "ErrInvalidData"},
+	{ErrInvalidRoute, "ErrInvalidRoute"},
+	{ErrTLSConfig, "ErrTLSConfig"},
+	{ErrPipelineNotFound, "ErrPipelineNotFound"},
+	{ErrCallDepth, "ErrCallDepth"},
+	{ErrIncludeCycle, "ErrIncludeCycle"},
+	{ErrCodecNotFound, "ErrCodecNotFound"},
+	{context.DeadlineExceeded, "DeadlineExceeded"},
+	{context.Canceled, "Canceled"},
+}
+
+// ErrorData converts error to structured data: filter, index, kind, message and status
+func ErrorData(err error) map[string]any {
+	res := map[string]any{"message": err.Error(), "kind": "Error"}
+	for _, k := range errorKinds {
+		if errors.Is(err, k.err) {
+			res["kind"] = k.kind
+			break
+		}
+	}
+	// innermost filter
+	for e := err; e != nil; {
+		var ferr *FilterError
+		if !errors.As(e, &ferr) {
+			break
+		}
+		res["filter"] = ferr.Filter
+		res["index"] = ferr.Index
+		e = ferr.Err
+	}
+	var serr *StatusError
+	if errors.As(err, &serr) {
+		res["status"] = serr.StatusCode
+	}
+	return res
+}
@@ -0,0 +1,119 @@
+package filterweb
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+type ForeachConfig struct {
+	Filter
+	Filters         []Config // filters to process each element
+	Concurrency     int      // max number of concurrent elements
+	ContinueOnError bool     // put error object to the result instead of failing
+	pipeline        *Pipeline
+}
+
+func (fc *ForeachConfig) New() Filter {
+	return &ForeachConfig{}
+}
+
+func (fc *ForeachConfig) Name() string {
+	return "foreach"
+}
+
+func (fc *ForeachConfig) Accepts() []string {
+	return []string{}
+}
+
+func (fc *ForeachConfig) Prep(config Config, data Data) error {
+	// defaults
+	fc.Concurrency = 1
+	err := decodeParams(config.Params, fc)
+	if err != nil {
+		return err
+	}
+	// mandatory
+	if len(fc.Filters) == 0 {
+		slog.Error("foreach filter requires 'filters' parameter")
+		return ErrMissingParams
+	}
+	if fc.Concurrency < 1 {
+		slog.Error("foreach filter requires positive 'concurrency'", "concurrency", fc.Concurrency)
+		return ErrInvalidParams
+	}
+	fc.pipeline, err = Compile(fc.Filters)
+	return err
+}
+
+func (fc *ForeachConfig) OutputContentType(input string) string {
+	return "application/json"
+}
+
+func (fc *ForeachConfig) subPipelines() []*Pipeline {
+	return []*Pipeline{fc.pipeline}
+}
+
+func (fc *ForeachConfig) Process(data Data) (Data, error) {
+	return fc.ProcessContext(context.Background(), data)
+}
+
+func (fc *ForeachConfig) ProcessContext(ctx context.Context, data Data) (Data, error) {
+	res := Data{ContentType: "application/json"}
+	items, ok := normalize(data.Data).([]any)
+	if !ok {
+		slog.Error("foreach filter requires array", "data", data.Data)
+		return res, ErrInvalidData
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make([]any, len(items))
+	var firstErr error
+	var once sync.Once
+	sem := make(chan struct{}, fc.Concurrency)
+	var wg sync.WaitGroup
+	for i, item := range items {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			out, err := fc.pipeline.Process(WithVars(ctx, nil), Data{ContentType: data.ContentType, Data: item})
+			if err != nil {
+				slog.Error("foreach item failed", "index", i, "error", err)
+				if fc.ContinueOnError {
+					results[i] = map[string]any{"index": i, "error": err.Error()}
+				} else {
+					once.Do(func() {
+						firstErr = fmt.Errorf("foreach item %d: %w", i, err)
+						cancel()
+					})
+				}
+				return
+			}
+			results[i] = out.Data
+		})
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return res, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return res, err
+	}
+	res.Data = results
+	return res, nil
+}
+
+func (fc *ForeachConfig) Post(config Config, data Data) error {
+	return nil
+}
+
+func init() {
+	RegisterFilter(&ForeachConfig{})
+}
@@ -0,0 +1,92 @@
+package filterweb
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestForeach_PrepAndProcess(t *testing.T) {
+	fc := &ForeachConfig{}
+	cfg := Config{Params: map[string]any{
+		"Concurrency": 2,
+		"Filters": []map[string]any{
+			{"Name": "jq", "Params": map[string]any{"Expression": ". * 2"}},
+		},
+	}}
+	if err := fc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	out, err := fc.Process(Data{ContentType: "application/json", Data: []any{1, 2, 3, 4, 5}})
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	res, ok := out.Data.([]any)
+	if !ok || len(res) != 5 {
+		t.Fatalf("unexpected output: %#v", out.Data)
+	}
+	for i, v := range res {
+		if arr, ok := v.([]any); !ok || arr[0] != (i+1)*2 {
+			t.Fatalf("unexpected element %d: %#v", i, v)
+		}
+	}
+}
+
+func TestForeach_Process_Error(t *testing.T) {
+	fc := &ForeachConfig{}
+	cfg := Config{Params: map[string]any{
+		"Filters": []map[string]any{
+			{"Name": "jq", "Params": map[string]any{"Expression": "if . == 2 then error(\"bad\") else . end"}},
+		},
+	}}
+	if err := fc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	if _, err := fc.Process(Data{Data: []any{1, 2, 3}}); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestForeach_Process_ContinueOnError(t *testing.T) {
+	fc := &ForeachConfig{}
+	cfg := Config{Params: map[string]any{
+		"ContinueOnError": true,
+		"Filters": []map[string]any{
+			{"Name": "jq", "Params": map[string]any{"Expression": "if . == 2 then error(\"bad\") else . end"}},
+		},
+	}}
+	if err := fc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	out, err := fc.Process(Data{Data: []any{1, 2, 3}})
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	res := out.Data.([]any)
+	errobj, ok := res[1].(map[string]any)
+	if !ok || errobj["index"] != 1 || errobj["error"] == "" {
+		t.Fatalf("unexpected error object: %#v", res[1])
+	}
+	if arr, ok := res[2].([]any); !ok || arr[0] != 3 {
+		t.Fatalf("unexpected element: %#v", res[2])
+	}
+}
+
+func TestForeach_Process_NotArray(t *testing.T) {
+	fc := &ForeachConfig{}
+	cfg := Config{Params: map[string]any{
+		"Filters": []map[string]any{{"Name": "jq", "Params": map[string]any{"Expression": "."}}},
+	}}
+	if err := fc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	if _, err := fc.Process(Data{Data: map[string]any{}}); !errors.Is(err, ErrInvalidData) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestForeach_Prep_MissingParams(t *testing.T) {
+	fc := &ForeachConfig{}
+	if err := fc.Prep(Config{Params: map[string]any{}}, Data{}); err != ErrMissingParams {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -3,6 +3,7 @@ module github.com/wtnb75/go-filterweb
 go 1.25.7
 
 require (
+	github.com/BurntSushi/toml v1.6.0
 	github.com/go-viper/mapstructure/v2 v2.5.0
 	github.com/goccy/go-yaml v1.19.2
 	github.com/invopop/jsonschema v0.13.0
@@ -1,25 +1,75 @@
 package filterweb
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
 	"io"
 	"log/slog"
+	"math/rand/v2"
 	"mime"
 	"net"
 	"net/http"
-
-	"github.com/go-viper/mapstructure/v2"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	tmplText "text/template"
+	"time"
 )
 
 type HTTPConfig struct {
 	Filter
-	Url         string            // request URL
-	UnixSocket  string            // unix socket path
-	ContentType string            // override content type
-	Verify      bool              // verify TLS certificates
-	Method      string            // HTTP method
-	Headers     map[string]string // HTTP headers
-	ExpectCode  []int             // expected HTTP status code
+	Url             string            // request URL (template)
+	UnixSocket      string            // unix socket path
+	ContentType     string            // override content type
+	Verify          bool              // verify TLS certificates
+	Method          string            // HTTP method
+	Headers         map[string]string // HTTP headers (template)
+	Query           map[string]string // query parameters (template)
+	ExpectCode      []int             // expected HTTP status code
+	Body            string            // request body (template)
+	BodyContentType string            // send input data encoded with this content type
+	CAFile          string            // CA certificates file (PEM)
+	ClientCert      string            // client certificate file (PEM)
+	ClientKey       string            // client key file (PEM)
+	ServerName      string            // override server name for TLS
+	MinTLSVersion   string            // minimum TLS version: 1.0, 1.1, 1.2 or 1.3
+	Timeout         time.Duration     // timeout of each attempt, 0 for no timeout
+	Retries         int               // max number of retries
+	Backoff         time.Duration     // initial backoff, doubled on each retry
+	MaxBackoff      time.Duration     // max backoff
+	RetryOn         []int             // status codes to retry
+	Paginate        string            // pagination: link, jq or page
+	NextExpr        string            // jq expression which returns next URL or cursor (jq)
+	PageParam       string            // query parameter of cursor (jq) or page number (page)
+	PageStart       int               // first page number (page)
+	ItemsExpr       string            // jq expression which returns items of each page
+	MaxPages        int               // max number of pages, 0 for unlimited
+	tmplurl         *tmplText.Template
+	tmplquery       map[string]*tmplText.Template
+	tmplheaders     map[string]*tmplText.Template
+	tmplbody        *tmplText.Template
+	nextquery       *jqQuery
+	itemsquery      *jqQuery
+}
+
+// httpRequest is a rendered request, sent on each attempt
+type httpRequest struct {
+	url    string
+	header http.Header
+	body   []byte
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
 }
 
 func (hc *HTTPConfig) New() Filter {
@@ -34,12 +84,27 @@ func (hc *HTTPConfig) Accepts() []string {
 	return []string{}
 }
 
+func (hc *HTTPConfig) OutputContentType(input string) string {
+	if hc.Paginate != "" {
+		return "" // depends on pages
+	}
+	return hc.ContentType
+}
+
 func (hc *HTTPConfig) Prep(config Config, data Data) error {
 	// defaults
 	hc.Method = http.MethodGet
 	hc.Verify = true
 	hc.ExpectCode = []int{200}
-	err := mapstructure.Decode(config.Params, hc)
+	hc.Backoff = 100 * time.Millisecond
+	hc.MaxBackoff = 10 * time.Second
+	hc.PageStart = 1
+	hc.MaxPages = 10
+	hc.RetryOn = []int{
+		http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout,
+	}
+	err := decodeParams(config.Params, hc)
 	if err != nil {
 		return err
 	}
@@ -48,33 +113,88 @@ func (hc *HTTPConfig) Prep(config Config, data Data) error {
 		slog.Error("http filter requires 'url' parameter")
 		return ErrMissingParams
 	}
+	if _, err = hc.transport(true); err != nil {
+		return err
+	}
+	if hc.tmplurl, err = parseText("url", hc.Url); err != nil {
+		slog.Error("url template parse error", "template", hc.Url, "error", err)
+		return err
+	}
+	hc.tmplquery = map[string]*tmplText.Template{}
+	for k, v := range hc.Query {
+		if hc.tmplquery[k], err = parseText(k, v); err != nil {
+			slog.Error("query template parse error", "key", k, "template", v, "error", err)
+			return err
+		}
+	}
+	hc.tmplheaders = map[string]*tmplText.Template{}
+	for k, v := range hc.Headers {
+		if hc.tmplheaders[k], err = parseText(k, v); err != nil {
+			slog.Error("header template parse error", "header", k, "template", v, "error", err)
+			return err
+		}
+	}
+	if hc.Body != "" {
+		if hc.tmplbody, err = parseText("body", hc.Body); err != nil {
+			slog.Error("body template parse error", "template", hc.Body, "error", err)
+			return err
+		}
+	}
+	return hc.prepPaginate()
+}
+
+func (hc *HTTPConfig) prepPaginate() (err error) {
+	switch hc.Paginate {
+	case "", "link":
+	case "jq":
+		if hc.NextExpr == "" {
+			slog.Error("http filter requires 'next_expr' parameter for jq pagination")
+			return ErrMissingParams
+		}
+		if hc.nextquery, err = compileQuery(hc.NextExpr); err != nil {
+			slog.Error("jq parse error", "expr", hc.NextExpr)
+			return err
+		}
+	case "page":
+		if hc.PageParam == "" {
+			slog.Error("http filter requires 'page_param' parameter for page pagination")
+			return ErrMissingParams
+		}
+	default:
+		slog.Error("unsupported pagination", "paginate", hc.Paginate)
+		return ErrInvalidParams
+	}
+	if hc.ItemsExpr != "" {
+		if hc.itemsquery, err = compileQuery(hc.ItemsExpr); err != nil {
+			slog.Error("jq parse error", "expr", hc.ItemsExpr)
+			return err
+		}
+	}
 	return nil
 }
 
 func (hc *HTTPConfig) Process(data Data) (Data, error) {
-	res := Data{}
-	var client *http.Client
-	if hc.UnixSocket != "" {
-		client = &http.Client{Transport: &http.Transport{
-			DialContext: func(_ context.Context, _, _ string) (net.Conn, error) {
-				return net.Dial("unix", hc.UnixSocket)
-			},
-		}}
-	} else {
-		client = &http.Client{}
-	}
-	httpreq, err := http.NewRequest(hc.Method, hc.Url, nil)
+	return hc.ProcessContext(context.Background(), data)
+}
+
+func (hc *HTTPConfig) ProcessContext(ctx context.Context, data Data) (Data, error) {
+	req, err := hc.makeRequest(ctx, data)
 	if err != nil {
-		slog.Error("http request(prep)", "method", hc.Method, "url", hc.Url, "err", err)
-		return res, ErrHTTPRequestFailed
+		return Data{}, err
 	}
-	for key, value := range hc.Headers {
-		httpreq.Header.Add(key, value)
+	if hc.Paginate != "" {
+		return hc.paginate(ctx, req)
 	}
-	httpres, err := client.Do(httpreq)
+	res, _, err := hc.fetch(ctx, req)
+	return res, err
+}
+
+// fetch sends request and decodes response body
+func (hc *HTTPConfig) fetch(ctx context.Context, req *httpRequest) (Data, http.Header, error) {
+	res := Data{}
+	httpres, err := hc.do(ctx, req)
 	if err != nil {
-		slog.Error("http request(do)", "method", hc.Method, "url", hc.Url, "err", err)
-		return res, ErrHTTPRequestFailed
+		return res, nil, err
 	}
 	defer httpres.Body.Close()
 	success := false
@@ -86,7 +206,7 @@ func (hc *HTTPConfig) Process(data Data) (Data, error) {
 	}
 	if !success {
 		slog.Error("status code", "expected", hc.ExpectCode, "actual", httpres.StatusCode)
-		return res, ErrHTTPStatusNotOK
+		return res, nil, &StatusError{StatusCode: httpres.StatusCode, Err: ErrHTTPStatusNotOK}
 	}
 	if hc.ContentType == "" {
 		ct := httpres.Header.Get("Content-Type")
@@ -94,25 +214,459 @@ func (hc *HTTPConfig) Process(data Data) (Data, error) {
 			mediaType, _, err := mime.ParseMediaType(ct)
 			slog.Debug("Parsed media type", "mediaType", mediaType, "err", err)
 			if err != nil {
-				return res, err
+				return res, nil, err
 			}
 			res.ContentType = mediaType
 		}
 	} else {
 		res.ContentType = hc.ContentType
 	}
+	if sc := getStreamCodec(res.ContentType); sc != nil {
+		// decode without buffering whole body
+		res.Data, err = sc.DecodeReader(httpres.Body)
+		if err != nil {
+			slog.Error("decode", "method", hc.Method, "url", req.url, "contenttype", res.ContentType, "err", err)
+		}
+		return res, httpres.Header, err
+	}
 	buf, err := io.ReadAll(httpres.Body)
 	if err != nil {
-		slog.Error("read body", "method", hc.Method, "url", hc.Url, "err", err)
-		return res, ErrHTTPRequestFailed
+		slog.Error("read body", "method", hc.Method, "url", req.url, "err", err)
+		return res, nil, ErrHTTPRequestFailed
 	}
 	res.Data, err = DecodeContentType(res.ContentType, buf)
 	if err != nil {
-		slog.Error("decode", "method", hc.Method, "url", hc.Url, "contenttype", res.ContentType, "err", err, "data", res.Data)
+		slog.Error("decode", "method", hc.Method, "url", req.url, "contenttype", res.ContentType, "err", err, "data", res.Data)
 	}
-	return res, err
+	return res, httpres.Header, err
+}
+
+// paginate fetches pages and concatenates items of each page
+func (hc *HTTPConfig) paginate(ctx context.Context, req *httpRequest) (Data, error) {
+	res := Data{ContentType: "application/json"}
+	items := []any{}
+	pageno := hc.PageStart
+	if hc.Paginate == "page" {
+		if err := req.setQuery(hc.PageParam, strconv.Itoa(pageno)); err != nil {
+			return res, err
+		}
+	}
+	for page := 1; hc.MaxPages == 0 || page <= hc.MaxPages; page++ {
+		pagedata, header, err := hc.fetch(ctx, req)
+		if err != nil {
+			return res, err
+		}
+		if pagedata.ContentType != "" {
+			res.ContentType = pagedata.ContentType
+		}
+		pageitems, err := hc.pageItems(ctx, pagedata.Data)
+		if err != nil {
+			return res, err
+		}
+		slog.Debug("page", "url", req.url, "page", page, "items", len(pageitems))
+		items = append(items, pageitems...)
+		next := ""
+		switch hc.Paginate {
+		case "link":
+			next = linkNext(header.Values("Link"))
+		case "jq":
+			outs, err := runQuery(ctx, hc.nextquery, pagedata.Data)
+			if err != nil {
+				return res, err
+			}
+			if len(outs) != 0 && outs[0] != nil && outs[0] != false {
+				next = fmt.Sprint(outs[0])
+			}
+			if next != "" && hc.PageParam != "" {
+				// cursor
+				if err = req.setQuery(hc.PageParam, next); err != nil {
+					return res, err
+				}
+				continue
+			}
+		case "page":
+			if len(pageitems) != 0 {
+				pageno++
+				if err = req.setQuery(hc.PageParam, strconv.Itoa(pageno)); err != nil {
+					return res, err
+				}
+				continue
+			}
+		}
+		if next == "" {
+			break
+		}
+		if err = req.setURL(next); err != nil {
+			return res, err
+		}
+	}
+	res.Data = items
+	return res, nil
+}
+
+// pageItems extracts items from a page
+func (hc *HTTPConfig) pageItems(ctx context.Context, data any) ([]any, error) {
+	if hc.itemsquery != nil {
+		outs, err := runQuery(ctx, hc.itemsquery, data)
+		if err != nil {
+			return nil, err
+		}
+		if len(outs) == 1 {
+			if arr, ok := outs[0].([]any); ok {
+				return arr, nil
+			}
+		}
+		return outs, nil
+	}
+	if arr, ok := data.([]any); ok {
+		return arr, nil
+	}
+	return []any{data}, nil
+}
+
+// linkNext returns rel="next" URL of Link headers
+func linkNext(links []string) string {
+	for _, header := range links {
+		for link := range strings.SplitSeq(header, ",") {
+			parts := strings.Split(link, ";")
+			target := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range parts[1:] {
+				key, val, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if strings.EqualFold(key, "rel") && slices.Contains(strings.Fields(strings.Trim(val, `"`)), "next") {
+					return strings.Trim(target, "<>")
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// setURL sets next URL, relative URL is resolved by current URL
+func (req *httpRequest) setURL(next string) error {
+	base, err := url.Parse(req.url)
+	if err != nil {
+		slog.Error("invalid url", "url", req.url, "error", err)
+		return ErrHTTPRequestFailed
+	}
+	u, err := base.Parse(next)
+	if err != nil {
+		slog.Error("invalid next url", "url", next, "error", err)
+		return ErrHTTPRequestFailed
+	}
+	req.url = u.String()
+	return nil
+}
+
+// setQuery sets query parameter of URL
+func (req *httpRequest) setQuery(key, value string) error {
+	u, err := url.Parse(req.url)
+	if err != nil {
+		slog.Error("invalid url", "url", req.url, "error", err)
+		return ErrHTTPRequestFailed
+	}
+	query := u.Query()
+	query.Set(key, value)
+	u.RawQuery = query.Encode()
+	req.url = u.String()
+	return nil
 }
 
+// makeRequest renders url, query, headers and body with input data
+func (hc *HTTPConfig) makeRequest(ctx context.Context, data Data) (*httpRequest, error) {
+	rawurl, err := renderText(ctx, hc.tmplurl, data.Data)
+	if err != nil {
+		slog.Error("url template error", "error", err)
+		return nil, err
+	}
+	if len(hc.tmplquery) != 0 {
+		u, err := url.Parse(rawurl)
+		if err != nil {
+			slog.Error("invalid url", "url", rawurl, "error", err)
+			return nil, ErrHTTPRequestFailed
+		}
+		query := u.Query()
+		for k, tmpl := range hc.tmplquery {
+			val, err := renderText(ctx, tmpl, data.Data)
+			if err != nil {
+				slog.Error("query template error", "key", k, "error", err)
+				return nil, err
+			}
+			query.Set(k, val)
+		}
+		u.RawQuery = query.Encode()
+		rawurl = u.String()
+	}
+	req := &httpRequest{url: rawurl, header: http.Header{}}
+	var bodyct string
+	if req.body, bodyct, err = hc.body(ctx, data); err != nil {
+		return nil, err
+	}
+	if bodyct != "" {
+		req.header.Set("Content-Type", bodyct)
+	}
+	for key, tmpl := range hc.tmplheaders {
+		val, err := renderText(ctx, tmpl, data.Data)
+		if err != nil {
+			slog.Error("header template error", "header", key, "error", err)
+			return nil, err
+		}
+		if http.CanonicalHeaderKey(key) == "Content-Type" {
+			req.header.Set(key, val)
+		} else {
+			req.header.Add(key, val)
+		}
+	}
+	return req, nil
+}
+
+// newRequest makes HTTP request for each attempt
+func (hc *HTTPConfig) newRequest(ctx context.Context, req *httpRequest) (*http.Request, error) {
+	var rd io.Reader
+	if req.body != nil {
+		rd = bytes.NewReader(req.body)
+	}
+	httpreq, err := http.NewRequestWithContext(ctx, hc.Method, req.url, rd)
+	if err != nil {
+		slog.Error("http request(prep)", "method", hc.Method, "url", req.url, "err", err)
+		return nil, ErrHTTPRequestFailed
+	}
+	httpreq.Header = req.header.Clone()
+	return httpreq, nil
+}
+
+// do sends request, retries on error or RetryOn status code
+func (hc *HTTPConfig) do(ctx context.Context, req *httpRequest) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		httpreq, err := hc.newRequest(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		var wait time.Duration
+		httpres, err := hc.client().Do(httpreq)
+		if err != nil {
+			slog.Error("http request(do)", "method", hc.Method, "url", req.url, "attempt", attempt, "err", err)
+			if attempt > hc.Retries || ctx.Err() != nil {
+				return nil, ErrHTTPRequestFailed
+			}
+		} else if !slices.Contains(hc.RetryOn, httpres.StatusCode) || attempt > hc.Retries {
+			slog.Debug("http response", "method", hc.Method, "url", req.url, "attempt", attempt, "status", httpres.StatusCode)
+			return httpres, nil
+		} else {
+			wait = retryAfter(httpres.Header.Get("Retry-After"))
+			io.Copy(io.Discard, httpres.Body)
+			httpres.Body.Close()
+		}
+		if wait == 0 {
+			wait = hc.backoff(attempt)
+		}
+		wait = min(wait, hc.MaxBackoff)
+		slog.Warn("http retry", "method", hc.Method, "url", req.url, "attempt", attempt, "retries", hc.Retries, "wait", wait)
+		select {
+		case <-ctx.Done():
+			return nil, ErrHTTPRequestFailed
+		case <-time.After(wait):
+		}
+	}
+}
+
+// backoff returns exponential backoff with jitter, 0 for no backoff
+func (hc *HTTPConfig) backoff(attempt int) time.Duration {
+	if hc.Backoff <= 0 {
+		return 0
+	}
+	wait := hc.Backoff << (attempt - 1)
+	if wait <= 0 || wait>>(attempt-1) != hc.Backoff || wait > hc.MaxBackoff {
+		// overflow or too long
+		wait = hc.MaxBackoff
+	}
+	return wait/2 + rand.N(wait/2+1)
+}
+
+// retryAfter parses Retry-After header (seconds or HTTP date)
+func retryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if sec, err := strconv.Atoi(value); err == nil && sec >= 0 {
+		return time.Duration(sec) * time.Second
+	}
+	if ts, err := http.ParseTime(value); err == nil {
+		return max(time.Until(ts), 0)
+	}
+	return 0
+}
+
+// transportKey identifies transport settings, filters with the same settings share connections
+type transportKey struct {
+	verify        bool
+	caFile        string
+	clientCert    string
+	clientKey     string
+	serverName    string
+	minTLSVersion string
+	unixSocket    string
+}
+
+// transportCheckInterval is minimum interval to check certificate file changes
+const transportCheckInterval = time.Second
+
+// sharedTransport is a transport and stamps of certificate files it was made from
+type sharedTransport struct {
+	transport *http.Transport
+	stamp     string
+	checked   time.Time
+}
+
+var transports = struct {
+	sync.Mutex
+	m map[transportKey]*sharedTransport
+}{m: map[transportKey]*sharedTransport{}}
+
+// fileStamp returns modification time and size of files, to detect rotated certificates
+func fileStamp(paths ...string) string {
+	var res strings.Builder
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if st, err := os.Stat(path); err == nil {
+			fmt.Fprintf(&res, "%s %d %d\n", path, st.ModTime().UnixNano(), st.Size())
+		}
+	}
+	return res.String()
+}
+
+// transport returns shared transport for the settings, new one if certificate files are changed.
+// files are checked at most every transportCheckInterval unless force.
+// current transport is returned with error if new one cannot be made.
+func (hc *HTTPConfig) transport(force bool) (*http.Transport, error) {
+	key := transportKey{
+		verify: hc.Verify, caFile: hc.CAFile, clientCert: hc.ClientCert, clientKey: hc.ClientKey,
+		serverName: hc.ServerName, minTLSVersion: hc.MinTLSVersion, unixSocket: hc.UnixSocket,
+	}
+	transports.Lock()
+	defer transports.Unlock()
+	old, ok := transports.m[key]
+	if ok && !force && time.Since(old.checked) < transportCheckInterval {
+		return old.transport, nil
+	}
+	stamp := fileStamp(hc.CAFile, hc.ClientCert, hc.ClientKey)
+	if ok {
+		old.checked = time.Now()
+		if old.stamp == stamp {
+			return old.transport, nil
+		}
+	}
+	tlsconfig, err := hc.makeTLSConfig()
+	if err != nil {
+		if ok {
+			return old.transport, err
+		}
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsconfig
+	if hc.UnixSocket != "" {
+		transport.Proxy = nil
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", key.unixSocket)
+		}
+	}
+	if ok {
+		slog.Info("certificate files changed, renew transport", "ca", hc.CAFile, "cert", hc.ClientCert)
+		old.transport.CloseIdleConnections()
+	}
+	transports.m[key] = &sharedTransport{transport: transport, stamp: stamp, checked: time.Now()}
+	return transport, nil
+}
+
+// client returns http client with current transport, keeps using old one on errors
+func (hc *HTTPConfig) client() *http.Client {
+	transport, err := hc.transport(false)
+	if err != nil {
+		slog.Error("renew transport failed, keep current one", "ca", hc.CAFile, "cert", hc.ClientCert, "error", err)
+	}
+	return &http.Client{Transport: transport, Timeout: hc.Timeout}
+}
+
+// makeTLSConfig makes TLS client config from parameters
+func (hc *HTTPConfig) makeTLSConfig() (*tls.Config, error) {
+	res := &tls.Config{
+		InsecureSkipVerify: !hc.Verify,
+		ServerName:         hc.ServerName,
+	}
+	if hc.MinTLSVersion != "" {
+		ver, ok := tlsVersions[hc.MinTLSVersion]
+		if !ok {
+			slog.Error("unsupported tls version", "version", hc.MinTLSVersion)
+			return nil, ErrTLSConfig
+		}
+		res.MinVersion = ver
+	}
+	if hc.CAFile != "" {
+		buf, err := os.ReadFile(hc.CAFile)
+		if err != nil {
+			slog.Error("read ca file", "path", hc.CAFile, "error", err)
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(buf) {
+			slog.Error("no certificate found", "path", hc.CAFile)
+			return nil, ErrTLSConfig
+		}
+		res.RootCAs = pool
+	}
+	if hc.ClientCert != "" || hc.ClientKey != "" {
+		if hc.ClientCert == "" || hc.ClientKey == "" {
+			slog.Error("http filter requires both 'client_cert' and 'client_key' parameter")
+			return nil, ErrMissingParams
+		}
+		cert, err := tls.LoadX509KeyPair(hc.ClientCert, hc.ClientKey)
+		if err != nil {
+			slog.Error("load client certificate", "cert", hc.ClientCert, "key", hc.ClientKey, "error", err)
+			return nil, err
+		}
+		res.Certificates = []tls.Certificate{cert}
+	}
+	return res, nil
+}
+
+// body makes request body and its content type
+func (hc *HTTPConfig) body(ctx context.Context, data Data) ([]byte, string, error) {
+	if hc.tmplbody != nil {
+		buf, err := renderText(ctx, hc.tmplbody, data.Data)
+		if err != nil {
+			slog.Error("body template error", "error", err)
+			return nil, "", err
+		}
+		ct := hc.BodyContentType
+		if ct == "" {
+			ct = "text/plain"
+		}
+		return []byte(buf), ct, nil
+	}
+	if hc.BodyContentType != "" {
+		if buf, ok := data.Data.([]byte); ok {
+			// already encoded
+			return buf, hc.BodyContentType, nil
+		}
+		buf, err := EncodeContentType(hc.BodyContentType, data.Data)
+		if err != nil {
+			slog.Error("body encode error", "contenttype", hc.BodyContentType, "error", err)
+			return nil, "", err
+		}
+		return buf, hc.BodyContentType, nil
+	}
+	return nil, "", nil
+}
+
+func (hc *HTTPConfig) definedVars() []string { return nil }
+func (hc *HTTPConfig) usedVars() []string    { return usedVars(hc.nextquery, hc.itemsquery) }
+
 func (hc *HTTPConfig) Post(config Config, data Data) error {
 	return nil
 }
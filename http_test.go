@@ -1,10 +1,23 @@
 package filterweb
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
 	"io"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestHTTP_Prep_MissingURL(t *testing.T) {
@@ -92,7 +105,7 @@ func TestHTTP_Process_StatusNotOK(t *testing.T) {
 	if err == nil {
 		t.Fatalf("expected ErrHTTPStatusNotOK")
 	}
-	if err != ErrHTTPStatusNotOK {
+	if !errors.Is(err, ErrHTTPStatusNotOK) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
@@ -114,3 +127,585 @@ func TestHTTP_Process_InvalidContentTypeHeader(t *testing.T) {
 		t.Fatalf("expected parse error for invalid Content-Type header")
 	}
 }
+
+func TestHTTP_ProcessContext_Timeout(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	hc := &HTTPConfig{}
+	cfg := Config{Params: map[string]any{"Url": srv.URL}}
+	if err := hc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := hc.ProcessContext(ctx, Data{})
+	if err != ErrHTTPRequestFailed {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestHTTP_Process_PostEncodedBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+		_, _ = io.Copy(w, r.Body)
+	}))
+	defer srv.Close()
+
+	hc := &HTTPConfig{}
+	cfg := Config{Params: map[string]any{"Url": srv.URL, "Method": "POST", "BodyContentType": "application/json"}}
+	if err := hc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	out, err := hc.Process(Data{ContentType: "application/json", Data: map[string]any{"name": "Alice"}})
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if out.ContentType != "application/json" {
+		t.Fatalf("unexpected content type: %v", out.ContentType)
+	}
+	m, ok := out.Data.(map[string]any)
+	if !ok || m["name"] != "Alice" {
+		t.Fatalf("unexpected data: %#v", out.Data)
+	}
+}
+
+func TestHTTP_Process_TemplateBody(t *testing.T) {
+	var gotBody, gotCt string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf, _ := io.ReadAll(r.Body)
+		gotBody = string(buf)
+		gotCt = r.Header.Get("Content-Type")
+	}))
+	defer srv.Close()
+
+	hc := &HTTPConfig{}
+	cfg := Config{Params: map[string]any{
+		"Url":     srv.URL,
+		"Method":  "PUT",
+		"Body":    "name={{.name}}",
+		"Headers": map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+	}}
+	if err := hc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	if _, err := hc.Process(Data{Data: map[string]any{"name": "Bob"}}); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if gotBody != "name=Bob" {
+		t.Fatalf("unexpected body: %q", gotBody)
+	}
+	if gotCt != "application/x-www-form-urlencoded" {
+		t.Fatalf("unexpected content type: %q", gotCt)
+	}
+}
+
+// writeCert writes certificate and key as PEM files
+func writeCert(t *testing.T, dir, name string, cert *x509.Certificate, key any) (string, string) {
+	t.Helper()
+	certfile := filepath.Join(dir, name+".pem")
+	keyfile := filepath.Join(dir, name+".key")
+	certpem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	if err := os.WriteFile(certfile, certpem, 0644); err != nil {
+		t.Fatalf("write cert: %v", err)
+	}
+	if key != nil {
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			t.Fatalf("marshal key: %v", err)
+		}
+		keypem := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+		if err := os.WriteFile(keyfile, keypem, 0600); err != nil {
+			t.Fatalf("write key: %v", err)
+		}
+	}
+	return certfile, keyfile
+}
+
+// selfSigned makes self-signed client certificate
+func selfSigned(t *testing.T, name string) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	return cert, key
+}
+
+func TestHTTP_Process_TLSVerify(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	hc := &HTTPConfig{}
+	if err := hc.Prep(Config{Params: map[string]any{"Url": srv.URL}}, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	if _, err := hc.Process(Data{}); err != ErrHTTPRequestFailed {
+		t.Fatalf("expected verification failure: %v", err)
+	}
+
+	hc = &HTTPConfig{}
+	if err := hc.Prep(Config{Params: map[string]any{"Url": srv.URL, "Verify": false}}, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	out, err := hc.Process(Data{})
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if out.String() != "ok" {
+		t.Fatalf("unexpected data: %v", out)
+	}
+}
+
+func TestHTTP_Process_TLSCAFile(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+	cafile, _ := writeCert(t, t.TempDir(), "ca", srv.Certificate(), nil)
+
+	hc := &HTTPConfig{}
+	cfg := Config{Params: map[string]any{
+		"Url": srv.URL, "CAFile": cafile, "ServerName": "example.com", "MinTLSVersion": "1.2",
+	}}
+	if err := hc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	out, err := hc.Process(Data{})
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if out.String() != "ok" {
+		t.Fatalf("unexpected data: %v", out)
+	}
+}
+
+func TestHTTP_Process_TLSClientCert(t *testing.T) {
+	cert, key := selfSigned(t, "client")
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+	srv.StartTLS()
+	defer srv.Close()
+	dir := t.TempDir()
+	cafile, _ := writeCert(t, dir, "ca", srv.Certificate(), nil)
+	certfile, keyfile := writeCert(t, dir, "client", cert, key)
+
+	hc := &HTTPConfig{}
+	cfg := Config{Params: map[string]any{
+		"Url": srv.URL, "CAFile": cafile, "ClientCert": certfile, "ClientKey": keyfile,
+	}}
+	if err := hc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	out, err := hc.Process(Data{})
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if out.String() != "client" {
+		t.Fatalf("unexpected data: %v", out)
+	}
+}
+
+func TestHTTP_Process_TLSClientCertRotated(t *testing.T) {
+	cert1, key1 := selfSigned(t, "client1")
+	cert2, key2 := selfSigned(t, "client2")
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	pool := x509.NewCertPool()
+	pool.AddCert(cert1)
+	pool.AddCert(cert2)
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+	srv.StartTLS()
+	defer srv.Close()
+	dir := t.TempDir()
+	cafile, _ := writeCert(t, dir, "ca", srv.Certificate(), nil)
+	certfile, keyfile := writeCert(t, dir, "client", cert1, key1)
+	hc := &HTTPConfig{}
+	cfg := Config{Params: map[string]any{
+		"Url": srv.URL, "CAFile": cafile, "ClientCert": certfile, "ClientKey": keyfile,
+	}}
+	if err := hc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	process := func() string {
+		out, err := hc.Process(Data{})
+		if err != nil {
+			t.Fatalf("Process failed: %v", err)
+		}
+		return out.String()
+	}
+	if name := process(); name != "client1" {
+		t.Fatalf("unexpected client certificate: %v", name)
+	}
+	writeCert(t, dir, "client", cert2, key2)
+	// mtime may not change within a short time
+	mtime := time.Now().Add(time.Minute)
+	os.Chtimes(certfile, mtime, mtime)
+	os.Chtimes(keyfile, mtime, mtime)
+	if name := process(); name != "client1" {
+		t.Fatalf("checked within interval: %v", name)
+	}
+	expireTransports()
+	if name := process(); name != "client2" {
+		t.Fatalf("not renewed: %v", name)
+	}
+
+	// broken files, keep using current transport
+	if err := os.WriteFile(certfile, []byte("broken"), 0644); err != nil {
+		t.Fatalf("write cert: %v", err)
+	}
+	mtime = mtime.Add(time.Minute)
+	os.Chtimes(certfile, mtime, mtime)
+	expireTransports()
+	if name := process(); name != "client2" {
+		t.Fatalf("unexpected client certificate: %v", name)
+	}
+	if err := (&HTTPConfig{}).Prep(cfg, Data{}); err == nil {
+		t.Fatalf("expected Prep error")
+	}
+}
+
+// expireTransports makes transports check certificate files on next use
+func expireTransports() {
+	transports.Lock()
+	defer transports.Unlock()
+	for _, st := range transports.m {
+		st.checked = time.Time{}
+	}
+}
+
+func TestHTTP_Prep_TLSConfigError(t *testing.T) {
+	hc := &HTTPConfig{}
+	cfg := Config{Params: map[string]any{"Url": "https://localhost", "MinTLSVersion": "0.9"}}
+	if err := hc.Prep(cfg, Data{}); err != ErrTLSConfig {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	hc = &HTTPConfig{}
+	cfg = Config{Params: map[string]any{"Url": "https://localhost", "ClientCert": "cert.pem"}}
+	if err := hc.Prep(cfg, Data{}); err != ErrMissingParams {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestHTTP_Process_Retry(t *testing.T) {
+	count := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		if count < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	hc := &HTTPConfig{}
+	cfg := Config{Params: map[string]any{"Url": srv.URL, "Retries": 3, "Backoff": "1ms"}}
+	if err := hc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	out, err := hc.Process(Data{})
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if out.String() != "ok" || count != 3 {
+		t.Fatalf("unexpected result: data=%v count=%d", out, count)
+	}
+}
+
+func TestHTTP_Process_RetryExhausted(t *testing.T) {
+	count := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	hc := &HTTPConfig{}
+	cfg := Config{Params: map[string]any{"Url": srv.URL, "Retries": 2, "Backoff": "1ms"}}
+	if err := hc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	if _, err := hc.Process(Data{}); !errors.Is(err, ErrHTTPStatusNotOK) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 3 {
+		t.Fatalf("unexpected attempts: %d", count)
+	}
+}
+
+func TestHTTP_Process_NoRetryOnOtherStatus(t *testing.T) {
+	count := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	hc := &HTTPConfig{}
+	cfg := Config{Params: map[string]any{"Url": srv.URL, "Retries": 2, "Backoff": "1ms"}}
+	if err := hc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	if _, err := hc.Process(Data{}); !errors.Is(err, ErrHTTPStatusNotOK) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("unexpected attempts: %d", count)
+	}
+}
+
+func TestHTTP_Process_Timeout(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	hc := &HTTPConfig{}
+	cfg := Config{Params: map[string]any{"Url": srv.URL, "Timeout": "50ms", "Retries": 1, "Backoff": "1ms"}}
+	if err := hc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	if _, err := hc.Process(Data{}); err != ErrHTTPRequestFailed {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestHTTP_Backoff(t *testing.T) {
+	hc := &HTTPConfig{Backoff: 0, MaxBackoff: 10 * time.Second}
+	for _, attempt := range []int{1, 5, 100} {
+		if d := hc.backoff(attempt); d != 0 {
+			t.Fatalf("unexpected backoff of attempt %d: %v", attempt, d)
+		}
+	}
+	hc.Backoff = 100 * time.Millisecond
+	if d := hc.backoff(3); d < 200*time.Millisecond || d > 400*time.Millisecond {
+		t.Fatalf("unexpected backoff: %v", d)
+	}
+	for _, attempt := range []int{10, 40, 64, 100} {
+		if d := hc.backoff(attempt); d < 5*time.Second || d > 10*time.Second {
+			t.Fatalf("unexpected backoff of attempt %d: %v", attempt, d)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	if d := retryAfter("3"); d != 3*time.Second {
+		t.Fatalf("unexpected duration: %v", d)
+	}
+	if d := retryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)); d <= 59*time.Minute {
+		t.Fatalf("unexpected duration: %v", d)
+	}
+	if d := retryAfter("invalid"); d != 0 {
+		t.Fatalf("unexpected duration: %v", d)
+	}
+}
+
+func TestHTTP_Process_TemplatedRequest(t *testing.T) {
+	var gotPath, gotQuery, gotHeader string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		gotQuery = r.URL.Query().Get("q")
+		gotHeader = r.Header.Get("X-User")
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	hc := &HTTPConfig{}
+	cfg := Config{Params: map[string]any{
+		"Url":     srv.URL + "/users/{{pathescape .id}}?fixed=1",
+		"Query":   map[string]string{"q": "{{.name}} & co"},
+		"Headers": map[string]string{"X-User": "{{.name}}"},
+	}}
+	if err := hc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	if _, err := hc.Process(Data{Data: map[string]any{"id": "a/b", "name": "Alice"}}); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if gotPath != "/users/a%2Fb" {
+		t.Fatalf("unexpected path: %q", gotPath)
+	}
+	if gotQuery != "Alice & co" {
+		t.Fatalf("unexpected query: %q", gotQuery)
+	}
+	if gotHeader != "Alice" {
+		t.Fatalf("unexpected header: %q", gotHeader)
+	}
+}
+
+func TestHTTP_Prep_InvalidTemplate(t *testing.T) {
+	hc := &HTTPConfig{}
+	cfg := Config{Params: map[string]any{"Url": "http://localhost/{{.id"}}
+	if err := hc.Prep(cfg, Data{}); err == nil {
+		t.Fatalf("expected template parse error")
+	}
+}
+
+func TestHTTP_Process_PaginateLink(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", `<?page=2>; rel="next", <?page=3>; rel="last"`)
+			_, _ = io.WriteString(w, `[1, 2]`)
+		case "2":
+			w.Header().Set("Link", `<?page=3>; rel="next"`)
+			_, _ = io.WriteString(w, `[3]`)
+		default:
+			_, _ = io.WriteString(w, `[4]`)
+		}
+	}))
+	defer srv.Close()
+
+	hc := &HTTPConfig{}
+	cfg := Config{Params: map[string]any{"Url": srv.URL, "Paginate": "link"}}
+	if err := hc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	out, err := hc.Process(Data{})
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if out.ContentType != "application/json" {
+		t.Fatalf("unexpected content type: %v", out.ContentType)
+	}
+	items, ok := out.Data.([]any)
+	if !ok || len(items) != 4 || items[3] != float64(4) {
+		t.Fatalf("unexpected data: %#v", out.Data)
+	}
+}
+
+func TestHTTP_Process_PaginateCursor(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			_, _ = io.WriteString(w, `{"items": ["a", "b"], "next": "xyz"}`)
+		case "xyz":
+			_, _ = io.WriteString(w, `{"items": ["c"], "next": null}`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	hc := &HTTPConfig{}
+	cfg := Config{Params: map[string]any{
+		"Url": srv.URL, "Paginate": "jq", "NextExpr": ".next", "PageParam": "cursor", "ItemsExpr": ".items",
+	}}
+	if err := hc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	out, err := hc.Process(Data{})
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	items, ok := out.Data.([]any)
+	if !ok || len(items) != 3 || items[2] != "c" {
+		t.Fatalf("unexpected data: %#v", out.Data)
+	}
+}
+
+func TestHTTP_Process_PaginatePageMax(t *testing.T) {
+	count := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `[`+r.URL.Query().Get("p")+`]`)
+	}))
+	defer srv.Close()
+
+	hc := &HTTPConfig{}
+	cfg := Config{Params: map[string]any{
+		"Url": srv.URL, "Paginate": "page", "PageParam": "p", "PageStart": 0, "MaxPages": 3,
+	}}
+	if err := hc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	out, err := hc.Process(Data{})
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	items, ok := out.Data.([]any)
+	if !ok || len(items) != 3 || items[0] != float64(0) || items[2] != float64(2) || count != 3 {
+		t.Fatalf("unexpected data: %#v count=%d", out.Data, count)
+	}
+}
+
+func TestHTTP_Prep_PaginateParams(t *testing.T) {
+	hc := &HTTPConfig{}
+	cfg := Config{Params: map[string]any{"Url": "http://localhost", "Paginate": "jq"}}
+	if err := hc.Prep(cfg, Data{}); err != ErrMissingParams {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	hc = &HTTPConfig{}
+	cfg = Config{Params: map[string]any{"Url": "http://localhost", "Paginate": "unknown"}}
+	if err := hc.Prep(cfg, Data{}); err != ErrInvalidParams {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestHTTP_Process_Vars(t *testing.T) {
+	var gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+	}))
+	defer srv.Close()
+
+	hc := &HTTPConfig{}
+	cfg := Config{Params: map[string]any{"Url": srv.URL + `/users/{{var "id"}}`}}
+	if err := hc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	ctx := WithVars(context.Background(), map[string]any{"id": 42})
+	if _, err := hc.ProcessContext(ctx, Data{}); err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if gotPath != "/users/42" {
+		t.Fatalf("unexpected path: %q", gotPath)
+	}
+}
@@ -1,15 +1,13 @@
 package filterweb
 
 import (
-	"bytes"
-	"encoding/csv"
-	"encoding/json"
-	"encoding/xml"
+	"context"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"time"
 
-	"github.com/goccy/go-yaml"
-	"github.com/joho/godotenv"
+	"github.com/go-viper/mapstructure/v2"
 )
 
 type Config struct {
@@ -20,12 +18,31 @@ type Config struct {
 type Data struct {
 	ContentType string
 	Data        any
+	Response    *Response // HTTP response control, kept across filters
+}
+
+// Response controls status code, headers and cookies of the HTTP response
+type Response struct {
+	Status  int
+	Headers http.Header
+	Cookies []*http.Cookie
 }
 
 type ConfigSchema struct {
-	Path    string
-	Method  string
-	Filters []Config
+	Path    string        // ServeMux pattern: "/users/{id}", "/files/{path...}". "/dir/" matches exactly, not the subtree
+	Method  string        // HTTP method, empty or "*" for any method
+	Filters []Config      // filters to process
+	Timeout time.Duration // timeout of whole filters, 0 for no timeout
+	Cache   *RouteCache   // response cache, nil for no cache
+}
+
+// RouteCache is a response cache setting of a route. only GET and HEAD requests are cached.
+type RouteCache struct {
+	TTL        time.Duration // time to live
+	Stale      time.Duration // serve expired response while revalidating
+	Headers    []string      // request headers included in cache key
+	MaxEntries int           // max number of entries, default 1000
+	MaxBytes   int64         // max total size of entries, 0 for no limit
 }
 
 type Filter interface {
@@ -37,13 +54,39 @@ type Filter interface {
 	Post(config Config, data Data) error
 }
 
-func (d Data) String() string {
-	if data, ok := d.Data.([]byte); ok {
-		return string(data)
-	} else if strdata, ok := d.Data.(string); ok {
-		return strdata
+// ConfigFile is a top-level config. a list of ConfigSchema is also accepted as routes.
+type ConfigFile struct {
+	Include   []string            // config files to include, relative to the file
+	Pipelines map[string][]Config // named pipelines for call filter
+	Routes    []ConfigSchema      // routes
+}
+
+// OutputFilter is a Filter which knows its output content type before processing
+type OutputFilter interface {
+	Filter
+	// OutputContentType returns output content type for input content type, "" for unknown
+	OutputContentType(input string) string
+}
+
+// ContextFilter is a Filter which supports cancellation and deadlines
+type ContextFilter interface {
+	Filter
+	ProcessContext(ctx context.Context, data Data) (Data, error)
+}
+
+// Bytes returns data encoded by its content type
+func (d Data) Bytes() ([]byte, error) {
+	switch val := d.Data.(type) {
+	case []byte:
+		return val, nil
+	case string:
+		return []byte(val), nil
 	}
-	buf, err := EncodeContentType(d.ContentType, d.Data)
+	return EncodeContentType(d.ContentType, d.Data)
+}
+
+func (d Data) String() string {
+	buf, err := d.Bytes()
 	if err != nil {
 		slog.Error("cannot convert to string", "contenttype", d.ContentType, "data", d.Data)
 		return ""
@@ -51,6 +94,28 @@ func (d Data) String() string {
 	return string(buf)
 }
 
+// decodeParams decodes filter parameters, durations can be written as "1s"
+func decodeParams(params map[string]any, result any) error {
+	return decode(params, result, false)
+}
+
+// decodeOptions decodes codec options, unknown keys are errors
+func decodeOptions(options map[string]any, result any) error {
+	return decode(options, result, true)
+}
+
+func decode(params map[string]any, result any, strict bool) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:  mapstructure.StringToTimeDurationHookFunc(),
+		ErrorUnused: strict,
+		Result:      result,
+	})
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(params)
+}
+
 var filters map[string]Filter = make(map[string]Filter)
 
 func RegisterFilter(f Filter) {
@@ -74,171 +139,48 @@ func ListFilters() []string {
 }
 
 func ProcessFilters(configs []Config) (Data, error) {
-	var data = Data{}
-	for _, config := range configs {
-		slog.Debug("processing filter", "config", config, "data", data)
-		filter, err := GetFilter(config.Name)
-		if err != nil {
-			return data, err
-		}
-		accepts := filter.Accepts()
-		accepted := false
-		if len(accepts) == 0 {
-			accepted = true
-		}
-		for _, accept := range accepts {
-			if accept == data.ContentType || accept == "*" {
-				accepted = true
-				break
-			}
-		}
-		if !accepted {
-			slog.Error("filter does not accept content type", "filter", filter.Name(), "data", data)
-			return data, ErrContentTypeMismatch
-		}
-		slog.Debug("Prep", "name", filter.Name(), "filter", filter, "data", data)
-		err = filter.Prep(config, data)
-		if err != nil {
-			return data, err
-		}
-		slog.Debug("Process", "name", filter.Name(), "filter", filter, "data", data)
-		data, err = filter.Process(data)
-		if err != nil {
-			return data, err
-		}
-		slog.Debug("Post", "name", filter.Name(), "filter", filter, "data", data)
-		err = filter.Post(config, data)
-		if err != nil {
-			return data, err
-		}
+	return ProcessFiltersData(configs, Data{})
+}
+
+// ProcessFiltersData runs filters with initial data (e.g. RequestData)
+func ProcessFiltersData(configs []Config, data Data) (Data, error) {
+	return ProcessFiltersContext(context.Background(), configs, data)
+}
+
+// ProcessFiltersContext runs filters with initial data, stops when ctx is done
+func ProcessFiltersContext(ctx context.Context, configs []Config, data Data) (Data, error) {
+	pipeline, err := Compile(configs)
+	if err != nil {
+		return data, err
 	}
-	return data, nil
+	return pipeline.Process(ctx, data)
 }
 
+// DecodeContentType decodes data with the codec of content type, data is returned as is for unknown types
 func DecodeContentType(contentType string, data []byte) (any, error) {
 	slog.Debug("decodeContentType", "contentType", contentType, "data", data)
-	var res any
-	switch contentType {
-	case "application/json":
-		err := json.Unmarshal(data, &res)
-		if err != nil {
-			return res, err
-		}
-	case "application/yaml", "text/yaml":
-		err := yaml.Unmarshal(data, &res)
-		if err != nil {
-			return res, err
-		}
-	case "text/xml", "application/xml":
-		err := xml.Unmarshal(data, &res)
-		if err != nil {
-			return res, err
-		}
-	case "text/csv":
-		buf := bytes.NewReader(data)
-		reader := csv.NewReader(buf)
-		hdr, err := reader.Read()
-		if err != nil {
-			slog.Error("csv read error", "error", err)
-			return res, err
-		}
-		resultdata := make([]map[string]any, 0)
-		for {
-			row, err := reader.Read()
-			if err != nil {
-				break
-			}
-			m := make(map[string]any)
-			for i, v := range row {
-				m[hdr[i]] = v
-			}
-			resultdata = append(resultdata, m)
-		}
-		res = resultdata
-	case "text/dotenv": // custom
-		buf := bytes.NewReader(data)
-		smap, err := godotenv.Parse(buf)
-		if err != nil {
-			slog.Error("dotenv parse error", "error", err)
-			return res, err
-		}
-		res = smap
-	default:
-		res = data
+	codec, err := GetCodec(contentType)
+	if err != nil {
+		return data, nil
+	}
+	res, err := codec.Decode(data)
+	if err != nil {
+		return res, err
 	}
 	slog.Debug("decoded", "contentType", contentType, "data", res)
 	return res, nil
 }
 
+// EncodeContentType encodes data with the codec of content type, formatted with %v for unknown types
 func EncodeContentType(contentType string, data any) ([]byte, error) {
 	slog.Debug("encodeContentType", "contentType", contentType, "data", data)
-	var res []byte
-	var err error
-	switch contentType {
-	case "application/json":
-		res, err = json.Marshal(data)
-		if err != nil {
-			return res, err
-		}
-	case "application/yaml", "text/yaml":
-		res, err = yaml.Marshal(data)
-		if err != nil {
-			return res, err
-		}
-	case "text/xml", "application/xml":
-		res, err = xml.Marshal(data)
-		if err != nil {
-			return res, err
-		}
-	case "text/csv":
-		buf := &bytes.Buffer{}
-		writer := csv.NewWriter(buf)
-		records, ok := data.([]map[string]any)
-		if !ok || len(records) == 0 {
-			return nil, fmt.Errorf("data is not []map[string]any or empty")
-		}
-		// write header
-		var header []string
-		for k := range records[0] {
-			header = append(header, k)
-		}
-		err = writer.Write(header)
-		if err != nil {
-			slog.Error("csv write error", "error", err)
-			return nil, err
-		}
-		// write records
-		for _, record := range records {
-			var row []string
-			for _, k := range header {
-				v, ok := record[k]
-				if !ok {
-					v = ""
-				}
-				row = append(row, fmt.Sprintf("%v", v))
-			}
-			err = writer.Write(row)
-			if err != nil {
-				slog.Error("csv write error", "error", err)
-				return nil, err
-			}
-		}
-		writer.Flush()
-		res = buf.Bytes()
-	case "text/dotenv": // custom
-		if smap, ok := data.(map[string]string); ok {
-			buf, err := godotenv.Marshal(smap)
-			if err != nil {
-				slog.Error("dotenv encode error", "error", err, "data", smap)
-				return nil, err
-			}
-			res = []byte(buf)
-		} else {
-			slog.Error("cannot encode to dotenv", "data", data)
-			return nil, ErrEncode
-		}
-	default:
-		res = fmt.Appendf(nil, "%v", data)
+	codec, err := GetCodec(contentType)
+	if err != nil {
+		return fmt.Appendf(nil, "%v", data), nil
+	}
+	res, err := codec.Encode(data)
+	if err != nil {
+		return res, err
 	}
 	slog.Debug("encoded", "contentType", contentType, "data", string(res))
 	return res, nil
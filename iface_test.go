@@ -0,0 +1,34 @@
+package filterweb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestProcessFiltersContext_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := ProcessFiltersContext(ctx, []Config{
+		{Name: "constant", Params: map[string]any{"Data": "hello"}},
+	}, Data{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestProcessFiltersContext_CommandTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := ProcessFiltersContext(ctx, []Config{
+		{Name: "command", Params: map[string]any{"Args": []string{"sleep", "10"}}},
+	}, Data{})
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("command is not killed: %v", elapsed)
+	}
+}
@@ -0,0 +1,140 @@
+package filterweb
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// iniCodec is a codec of INI files. keys before the first section are at top level,
+// and each section is a nested map. values are strings.
+type iniCodec struct{}
+
+func (iniCodec) ContentTypes() []string { return []string{"text/x-ini", "text/ini"} }
+func (iniCodec) Extensions() []string   { return []string{"ini"} }
+
+// iniValue removes quotes of the value
+func iniValue(s string) string {
+	if len(s) >= 2 && (s[0] == '"' && s[len(s)-1] == '"') {
+		if v, err := strconv.Unquote(s); err == nil {
+			return v
+		}
+	}
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+func (iniCodec) Decode(data []byte) (any, error) {
+	res := map[string]any{}
+	current := res
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineno := 0
+	for scanner.Scan() {
+		lineno++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+		if line[0] == '[' {
+			name, ok := strings.CutSuffix(line[1:], "]")
+			if !ok {
+				slog.Error("ini invalid section", "line", lineno, "text", line)
+				return nil, fmt.Errorf("%w: line %d: invalid section", ErrDecode, lineno)
+			}
+			name = strings.TrimSpace(name)
+			section, ok := res[name].(map[string]any)
+			if !ok {
+				if _, exists := res[name]; exists {
+					slog.Error("ini section conflicts with key", "line", lineno, "section", name)
+					return nil, fmt.Errorf("%w: line %d: section %s conflicts with key", ErrDecode, lineno, name)
+				}
+				section = map[string]any{}
+				res[name] = section
+			}
+			current = section
+			continue
+		}
+		idx := strings.IndexAny(line, "=:")
+		if idx <= 0 {
+			slog.Error("ini invalid line", "line", lineno, "text", line)
+			return nil, fmt.Errorf("%w: line %d: key=value expected", ErrDecode, lineno)
+		}
+		key := strings.TrimSpace(line[:idx])
+		current[key] = iniValue(strings.TrimSpace(line[idx+1:]))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// iniFormat formats a value, quoted if needed
+func iniFormat(v any) (string, error) {
+	switch val := v.(type) {
+	case nil:
+		return "", nil
+	case map[string]any, []any:
+		return "", fmt.Errorf("%w: nested value in ini section", ErrEncode)
+	case string:
+		if val != strings.TrimSpace(val) || strings.ContainsAny(val, "\n\";#") {
+			return strconv.Quote(val), nil
+		}
+		return val, nil
+	}
+	return fmt.Sprint(v), nil
+}
+
+// writeKeys writes key=value lines
+func writeKeys(buf *bytes.Buffer, m map[string]any, keys []string) error {
+	for _, k := range keys {
+		v, err := iniFormat(m[k])
+		if err != nil {
+			slog.Error("ini encode error", "key", k, "error", err)
+			return err
+		}
+		fmt.Fprintf(buf, "%s = %s\n", k, v)
+	}
+	return nil
+}
+
+func (iniCodec) Encode(data any) ([]byte, error) {
+	root, ok := normalize(data).(map[string]any)
+	if !ok {
+		slog.Error("ini requires map", "data", data)
+		return nil, fmt.Errorf("%w: data is not a map", ErrEncode)
+	}
+	var keys, sections []string
+	for _, k := range slices.Sorted(maps.Keys(root)) {
+		if _, ok := normalize(root[k]).(map[string]any); ok {
+			sections = append(sections, k)
+		} else {
+			keys = append(keys, k)
+		}
+	}
+	buf := &bytes.Buffer{}
+	if err := writeKeys(buf, root, keys); err != nil {
+		return nil, err
+	}
+	for i, name := range sections {
+		if i != 0 || len(keys) != 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(buf, "[%s]\n", name)
+		section := normalize(root[name]).(map[string]any)
+		if err := writeKeys(buf, section, slices.Sorted(maps.Keys(section))); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func init() {
+	RegisterCodec(iniCodec{})
+}
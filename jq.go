@@ -1,16 +1,18 @@
 package filterweb
 
 import (
+	"context"
 	"log/slog"
+	"slices"
+	"strings"
 
-	"github.com/go-viper/mapstructure/v2"
 	"github.com/itchyny/gojq"
 )
 
 type JqConfig struct {
 	Filter
 	Expression string
-	query      *gojq.Query
+	query      *jqQuery
 }
 
 func (jc *JqConfig) New() Filter {
@@ -25,8 +27,12 @@ func (jc *JqConfig) Accepts() []string {
 	return []string{}
 }
 
+func (jc *JqConfig) OutputContentType(input string) string {
+	return "application/json"
+}
+
 func (jc *JqConfig) Prep(config Config, data Data) (err error) {
-	if err = mapstructure.Decode(config.Params, jc); err != nil {
+	if err = decodeParams(config.Params, jc); err != nil {
 		slog.Error("mapstructure decode", "type", jc.Name(), "params", config.Params)
 		return err
 	}
@@ -34,7 +40,7 @@ func (jc *JqConfig) Prep(config Config, data Data) (err error) {
 		slog.Error("jq filter requires 'expression' parameter")
 		return ErrMissingParams
 	}
-	if jc.query, err = gojq.Parse(jc.Expression); err != nil {
+	if jc.query, err = compileQuery(jc.Expression); err != nil {
 		slog.Error("jq filter parse error", "expr", jc.Expression)
 		return err
 	}
@@ -42,7 +48,66 @@ func (jc *JqConfig) Prep(config Config, data Data) (err error) {
 }
 
 func (jc *JqConfig) Process(data Data) (Data, error) {
-	iter := jc.query.Run(data.Data)
+	return jc.ProcessContext(context.Background(), data)
+}
+
+func (jc *JqConfig) ProcessContext(ctx context.Context, data Data) (Data, error) {
+	res, err := runQuery(ctx, jc.query, data.Data)
+	if err != nil {
+		return Data{}, err
+	}
+	return Data{ContentType: "application/json", Data: res}, nil
+}
+
+// jqQuery is a compiled jq query and names of variables referenced in it
+type jqQuery struct {
+	code *gojq.Code
+	vars []string
+}
+
+// compileQuery parses and compiles jq expression. free variables referenced as $name
+// are bound at run time, null if not set.
+func compileQuery(expr string) (*jqQuery, error) {
+	query, err := gojq.Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	res := &jqQuery{}
+	var names []string
+	for {
+		res.code, err = gojq.Compile(query, gojq.WithVariables(names))
+		if err == nil {
+			return res, nil
+		}
+		// the compiler resolves scope of variables, free ones are reported one by one
+		name, ok := strings.CutPrefix(err.Error(), "variable not defined: $")
+		if !ok || slices.Contains(res.vars, name) {
+			return nil, err
+		}
+		res.vars = append(res.vars, name)
+		names = append(names, "$"+name)
+	}
+}
+
+// usedVars returns variables referenced in queries
+func usedVars(queries ...*jqQuery) []string {
+	var res []string
+	for _, q := range queries {
+		if q != nil {
+			res = append(res, q.vars...)
+		}
+	}
+	return res
+}
+
+// runQuery runs jq query and collects all outputs, variables are available as $name
+func runQuery(ctx context.Context, query *jqQuery, input any) ([]any, error) {
+	vars := VarsFromContext(ctx)
+	values := make([]any, len(query.vars))
+	for i, name := range query.vars {
+		values[i], _ = vars.Get(name)
+	}
+	iter := query.code.RunWithContext(ctx, input, values...)
 	res := []any{}
 	for {
 		v, ok := iter.Next()
@@ -54,13 +119,16 @@ func (jc *JqConfig) Process(data Data) (Data, error) {
 				break
 			}
 			slog.Error("jq processing error", "error", err)
-			return Data{}, err
+			return nil, err
 		}
 		res = append(res, v)
 	}
-	return Data{ContentType: "application/json", Data: res}, nil
+	return res, nil
 }
 
+func (jc *JqConfig) definedVars() []string { return nil }
+func (jc *JqConfig) usedVars() []string    { return usedVars(jc.query) }
+
 func (jc *JqConfig) Post(config Config, data Data) error {
 	return nil
 }
@@ -0,0 +1,191 @@
+package filterweb
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Cache is an in-memory LRU cache of Data with stale-while-revalidate and single-flight
+type Cache struct {
+	MaxEntries int   // max number of entries, 0 for no limit
+	MaxBytes   int64 // max total size of entries, 0 for no limit
+	mu         sync.Mutex
+	ll         *list.List // front is most recently used
+	items      map[string]*list.Element
+	size       int64
+	flights    map[string]*flight
+}
+
+type cacheEntry struct {
+	key     string
+	data    Data
+	size    int64
+	expires time.Time // fresh until
+	stale   time.Time // can be served while revalidating until
+}
+
+// flight is a running process of a key, concurrent misses wait for it
+type flight struct {
+	done    chan struct{}
+	data    Data
+	err     error
+	private bool // result is not shared with other callers
+}
+
+// NewCache returns an empty cache
+func NewCache(maxEntries int, maxBytes int64) *Cache {
+	return &Cache{
+		MaxEntries: maxEntries,
+		MaxBytes:   maxBytes,
+		ll:         list.New(),
+		items:      map[string]*list.Element{},
+		flights:    map[string]*flight{},
+	}
+}
+
+// Len returns number of entries
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// Do returns cached data of key, or runs fn and stores its result for ttl.
+// expired data is served for stale duration while fn runs in background.
+// errors and responses with cookies or error status are not cached nor shared.
+func (c *Cache) Do(ctx context.Context, key string, ttl, stale time.Duration, fn func(context.Context) (Data, error)) (Data, error) {
+	now := time.Now()
+	c.mu.Lock()
+	if elem, ok := c.items[key]; ok {
+		ent := elem.Value.(*cacheEntry)
+		if now.Before(ent.expires) {
+			c.ll.MoveToFront(elem)
+			c.mu.Unlock()
+			slog.Debug("cache hit", "key", key)
+			return ent.data, nil
+		}
+		if now.Before(ent.stale) {
+			c.ll.MoveToFront(elem)
+			if _, ok := c.flights[key]; !ok {
+				slog.Debug("cache stale, revalidating", "key", key)
+				f := c.start(key)
+				// keep the deadline of the caller (e.g. route timeout), or ttl if none
+				timeout := ttl
+				if deadline, ok := ctx.Deadline(); ok {
+					timeout = time.Until(deadline)
+				}
+				bgctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+				go func() {
+					defer cancel()
+					c.run(bgctx, key, ttl, stale, f, fn)
+				}()
+			}
+			c.mu.Unlock()
+			return ent.data, nil
+		}
+	}
+	if f, ok := c.flights[key]; ok {
+		c.mu.Unlock()
+		slog.Debug("cache miss, waiting", "key", key)
+		select {
+		case <-f.done:
+			if isContextError(f.err) && ctx.Err() == nil {
+				// the leading caller has gone away, run again on behalf of this caller
+				slog.Debug("cache leader canceled, retrying", "key", key, "error", f.err)
+				return c.Do(ctx, key, ttl, stale, fn)
+			}
+			if f.private && f.err == nil {
+				// response for the leading caller only (e.g. cookies), run again for this caller
+				slog.Debug("cache private response, not shared", "key", key)
+				return fn(ctx)
+			}
+			return f.data, f.err
+		case <-ctx.Done():
+			return Data{}, ctx.Err()
+		}
+	}
+	slog.Debug("cache miss", "key", key)
+	f := c.start(key)
+	c.mu.Unlock()
+	c.run(ctx, key, ttl, stale, f, fn)
+	return f.data, f.err
+}
+
+// start registers flight of key. c.mu must be held.
+func (c *Cache) start(key string) *flight {
+	f := &flight{done: make(chan struct{})}
+	c.flights[key] = f
+	return f
+}
+
+// run processes fn and stores the result
+func (c *Cache) run(ctx context.Context, key string, ttl, stale time.Duration, f *flight, fn func(context.Context) (Data, error)) {
+	f.data, f.err = fn(ctx)
+	f.private = f.err == nil && !cacheable(f.data)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.flights, key)
+	close(f.done)
+	if f.err != nil {
+		slog.Debug("not cached", "key", key, "error", f.err)
+		return
+	}
+	if f.private {
+		slog.Debug("not cached, private response", "key", key, "status", f.data.Response.Status)
+		c.remove(key)
+		return
+	}
+	c.add(key, f.data, ttl, stale)
+}
+
+// add stores data and evicts least recently used entries. c.mu must be held.
+func (c *Cache) add(key string, data Data, ttl, stale time.Duration) {
+	now := time.Now()
+	ent := &cacheEntry{
+		key: key, data: data, size: int64(len(key) + len(data.String())),
+		expires: now.Add(ttl), stale: now.Add(ttl + stale),
+	}
+	if c.MaxBytes > 0 && ent.size > c.MaxBytes {
+		slog.Debug("too large to cache", "key", key, "size", ent.size)
+		c.remove(key)
+		return
+	}
+	c.remove(key)
+	c.items[key] = c.ll.PushFront(ent)
+	c.size += ent.size
+	for (c.MaxEntries > 0 && c.ll.Len() > c.MaxEntries) || (c.MaxBytes > 0 && c.size > c.MaxBytes) {
+		old := c.ll.Back().Value.(*cacheEntry)
+		slog.Debug("cache evicted", "key", old.key)
+		c.remove(old.key)
+	}
+}
+
+// remove deletes entry of key. c.mu must be held.
+func (c *Cache) remove(key string) {
+	if elem, ok := c.items[key]; ok {
+		c.ll.Remove(elem)
+		delete(c.items, key)
+		c.size -= elem.Value.(*cacheEntry).size
+	}
+}
+
+// cacheable reports whether data can be shared with other requests:
+// no cookies are set and the status is 2xx or 3xx.
+func cacheable(data Data) bool {
+	if data.Response == nil {
+		return true
+	}
+	if len(data.Response.Cookies) != 0 {
+		return false
+	}
+	return data.Response.Status == 0 || (data.Response.Status >= 200 && data.Response.Status < 400)
+}
+
+// isContextError reports whether err is caused by cancellation or deadline of context
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
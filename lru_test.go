@@ -0,0 +1,219 @@
+package filterweb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func constData(s string) func(context.Context) (Data, error) {
+	return func(context.Context) (Data, error) {
+		return Data{ContentType: "text/plain", Data: s}, nil
+	}
+}
+
+func TestCache_Do_Hit(t *testing.T) {
+	c := NewCache(10, 0)
+	var calls atomic.Int32
+	fn := func(context.Context) (Data, error) {
+		calls.Add(1)
+		return Data{Data: "value"}, nil
+	}
+	for range 3 {
+		out, err := c.Do(context.Background(), "k", time.Minute, 0, fn)
+		if err != nil || out.Data != "value" {
+			t.Fatalf("unexpected result: %v %v", out, err)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("unexpected calls: %d", calls.Load())
+	}
+}
+
+func TestCache_Do_Error(t *testing.T) {
+	c := NewCache(10, 0)
+	bad := errors.New("bad")
+	_, err := c.Do(context.Background(), "k", time.Minute, 0, func(context.Context) (Data, error) {
+		return Data{}, bad
+	})
+	if err != bad {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Len() != 0 {
+		t.Fatalf("error is cached")
+	}
+}
+
+func TestCache_Do_Evict(t *testing.T) {
+	c := NewCache(2, 0)
+	ctx := context.Background()
+	c.Do(ctx, "a", time.Minute, 0, constData("a"))
+	c.Do(ctx, "b", time.Minute, 0, constData("b"))
+	c.Do(ctx, "a", time.Minute, 0, constData("x")) // a is recently used
+	c.Do(ctx, "c", time.Minute, 0, constData("c"))
+	if c.Len() != 2 {
+		t.Fatalf("unexpected length: %d", c.Len())
+	}
+	if out, _ := c.Do(ctx, "a", time.Minute, 0, constData("new")); out.Data != "a" {
+		t.Fatalf("a is evicted: %v", out.Data)
+	}
+	if out, _ := c.Do(ctx, "b", time.Minute, 0, constData("new")); out.Data != "new" {
+		t.Fatalf("b is not evicted: %v", out.Data)
+	}
+}
+
+func TestCache_Do_MaxBytes(t *testing.T) {
+	c := NewCache(0, 20)
+	ctx := context.Background()
+	c.Do(ctx, "a", time.Minute, 0, constData("0123456789"))
+	c.Do(ctx, "b", time.Minute, 0, constData("0123456789"))
+	if c.Len() != 1 {
+		t.Fatalf("unexpected length: %d", c.Len())
+	}
+	c.Do(ctx, "c", time.Minute, 0, constData("too large to store in the cache"))
+	if c.Len() != 1 {
+		t.Fatalf("unexpected length: %d", c.Len())
+	}
+}
+
+func TestCache_Do_Stale(t *testing.T) {
+	c := NewCache(10, 0)
+	ctx := context.Background()
+	c.Do(ctx, "k", 10*time.Millisecond, time.Minute, constData("old"))
+	time.Sleep(20 * time.Millisecond)
+	refreshed := make(chan struct{})
+	out, err := c.Do(ctx, "k", time.Minute, time.Minute, func(context.Context) (Data, error) {
+		defer close(refreshed)
+		return Data{Data: "new"}, nil
+	})
+	if err != nil || out.Data != "old" {
+		t.Fatalf("stale data is not served: %v %v", out, err)
+	}
+	<-refreshed
+	time.Sleep(10 * time.Millisecond)
+	if out, _ = c.Do(ctx, "k", time.Minute, 0, constData("x")); out.Data != "new" {
+		t.Fatalf("not revalidated: %v", out.Data)
+	}
+}
+
+func TestCache_Do_SingleFlight(t *testing.T) {
+	c := NewCache(10, 0)
+	var calls atomic.Int32
+	release := make(chan struct{})
+	fn := func(context.Context) (Data, error) {
+		calls.Add(1)
+		<-release
+		return Data{Data: "value"}, nil
+	}
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			out, err := c.Do(context.Background(), "k", time.Minute, 0, fn)
+			if err != nil || out.Data != "value" {
+				t.Errorf("unexpected result: %v %v", out, err)
+			}
+		})
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if calls.Load() != 1 {
+		t.Fatalf("unexpected calls: %d", calls.Load())
+	}
+}
+
+func TestCache_Do_LeaderCanceled(t *testing.T) {
+	c := NewCache(10, 0)
+	started := make(chan struct{})
+	leader := func(ctx context.Context) (Data, error) {
+		close(started)
+		<-ctx.Done()
+		return Data{}, ctx.Err()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := c.Do(ctx, "k", time.Minute, 0, leader)
+		done <- err
+	}()
+	<-started
+	res := make(chan Data)
+	go func() {
+		out, err := c.Do(context.Background(), "k", time.Minute, 0, constData("value"))
+		if err != nil {
+			t.Errorf("waiter failed: %v", err)
+		}
+		res <- out
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected leader error: %v", err)
+	}
+	if out := <-res; out.Data != "value" {
+		t.Fatalf("unexpected waiter result: %v", out)
+	}
+}
+
+func TestCache_Do_PrivateNotShared(t *testing.T) {
+	c := NewCache(10, 0)
+	release := make(chan struct{})
+	var calls atomic.Int32
+	fn := func(context.Context) (Data, error) {
+		n := calls.Add(1)
+		if n == 1 {
+			<-release
+		}
+		return Data{Data: n, Response: &Response{Cookies: []*http.Cookie{{Name: "id", Value: fmt.Sprint(n)}}}}, nil
+	}
+	res := make(chan Data, 2)
+	for range 2 {
+		go func() {
+			out, err := c.Do(context.Background(), "k", time.Minute, 0, fn)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			res <- out
+		}()
+		time.Sleep(20 * time.Millisecond)
+	}
+	close(release)
+	a, b := <-res, <-res
+	if a.Data == b.Data {
+		t.Fatalf("private response is shared: %v %v", a, b)
+	}
+	if c.Len() != 0 {
+		t.Fatalf("private response is cached")
+	}
+}
+
+func TestCache_Do_StaleDeadline(t *testing.T) {
+	c := NewCache(10, 0)
+	c.Do(context.Background(), "k", 10*time.Millisecond, time.Minute, constData("old"))
+	time.Sleep(20 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	done := make(chan error)
+	out, err := c.Do(ctx, "k", time.Minute, time.Minute, func(ctx context.Context) (Data, error) {
+		// hanging upstream
+		<-ctx.Done()
+		done <- ctx.Err()
+		return Data{}, ctx.Err()
+	})
+	if err != nil || out.Data != "old" {
+		t.Fatalf("stale data is not served: %v %v", out, err)
+	}
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("revalidation has no deadline")
+	}
+}
@@ -0,0 +1,62 @@
+package filterweb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// ndjsonCodec is a codec of newline delimited JSON (JSON Lines).
+// the document is decoded to an array of values, and an array is encoded one value per line.
+type ndjsonCodec struct{}
+
+func (ndjsonCodec) ContentTypes() []string {
+	return []string{"application/x-ndjson", "application/jsonl", "application/x-jsonlines"}
+}
+
+func (ndjsonCodec) Extensions() []string { return []string{"ndjson", "jsonl"} }
+
+func (c ndjsonCodec) Decode(data []byte) (any, error) {
+	return c.DecodeReader(bytes.NewReader(data))
+}
+
+// DecodeReader decodes values one by one, without reading whole input first
+func (ndjsonCodec) DecodeReader(r io.Reader) (any, error) {
+	decoder := json.NewDecoder(r)
+	res := []any{}
+	for {
+		var v any
+		err := decoder.Decode(&v)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			slog.Error("ndjson decode error", "index", len(res), "error", err)
+			return nil, fmt.Errorf("%w: line %d: %v", ErrDecode, len(res)+1, err)
+		}
+		res = append(res, v)
+	}
+	return res, nil
+}
+
+func (ndjsonCodec) Encode(data any) ([]byte, error) {
+	values, ok := normalize(data).([]any)
+	if !ok {
+		values = []any{data}
+	}
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	for i, v := range values {
+		if err := encoder.Encode(v); err != nil {
+			slog.Error("ndjson encode error", "index", i, "error", err)
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func init() {
+	RegisterCodec(ndjsonCodec{})
+}
@@ -0,0 +1,97 @@
+package filterweb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestNDJSON_Decode(t *testing.T) {
+	res, err := DecodeContentType("application/x-ndjson", []byte("{\"a\":1}\n\n[2]\n\"three\"\n"))
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	expected := []any{map[string]any{"a": 1.0}, []any{2.0}, "three"}
+	if !reflect.DeepEqual(res, expected) {
+		t.Fatalf("unexpected result: %#v", res)
+	}
+}
+
+func TestNDJSON_Decode_Error(t *testing.T) {
+	_, err := DecodeContentType("application/jsonl", []byte("{\"a\":1}\n{broken\n"))
+	if !errors.Is(err, ErrDecode) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestNDJSON_Encode(t *testing.T) {
+	buf, err := EncodeContentType("application/x-ndjson", []any{map[string]any{"a": "<b>"}, 2})
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	if string(buf) != "{\"a\":\"<b>\"}\n2\n" {
+		t.Fatalf("unexpected output: %q", buf)
+	}
+	buf, err = EncodeContentType("application/x-ndjson", map[string]any{"single": true})
+	if err != nil || string(buf) != "{\"single\":true}\n" {
+		t.Fatalf("unexpected output: %q %v", buf, err)
+	}
+}
+
+func TestNDJSON_Command(t *testing.T) {
+	cc := &CommandConfig{}
+	cfg := Config{Params: map[string]any{
+		"Args":        []string{"sh", "-c", `for i in 1 2 3; do echo "{\"n\":$i}"; done`},
+		"ContentType": "application/x-ndjson",
+	}}
+	if err := cc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	out, err := cc.ProcessContext(context.Background(), Data{Data: ""})
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if items, ok := out.Data.([]any); !ok || len(items) != 3 {
+		t.Fatalf("unexpected output: %#v", out.Data)
+	}
+}
+
+func TestNDJSON_Command_Failed(t *testing.T) {
+	cc := &CommandConfig{}
+	cfg := Config{Params: map[string]any{
+		"Args":        []string{"sh", "-c", `echo '{"n":1}'; exit 1`},
+		"ContentType": "application/x-ndjson",
+	}}
+	if err := cc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	if _, err := cc.Process(Data{Data: ""}); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestNDJSON_HTTP(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson; charset=utf-8")
+		for i := range 100 {
+			fmt.Fprintf(w, "{\"n\":%d}\n", i)
+		}
+	}))
+	defer ts.Close()
+	hc := &HTTPConfig{}
+	if err := hc.Prep(Config{Params: map[string]any{"Url": ts.URL}}, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	out, err := hc.Process(Data{})
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	items, ok := out.Data.([]any)
+	if !ok || len(items) != 100 || out.ContentType != "application/x-ndjson" {
+		t.Fatalf("unexpected output: %v %#v", out.ContentType, out.Data)
+	}
+}
@@ -0,0 +1,118 @@
+package filterweb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+)
+
+type ParallelConfig struct {
+	Filter
+	Branches map[string][]Config // named filters processed concurrently
+	OnError  string              // fail, ignore or null
+	branches map[string]*Pipeline
+}
+
+func (pc *ParallelConfig) New() Filter {
+	return &ParallelConfig{}
+}
+
+func (pc *ParallelConfig) Name() string {
+	return "parallel"
+}
+
+func (pc *ParallelConfig) Accepts() []string {
+	return []string{}
+}
+
+func (pc *ParallelConfig) Prep(config Config, data Data) error {
+	// defaults
+	pc.OnError = "fail"
+	err := decodeParams(config.Params, pc)
+	if err != nil {
+		return err
+	}
+	// mandatory
+	if len(pc.Branches) == 0 {
+		slog.Error("parallel filter requires 'branches' parameter")
+		return ErrMissingParams
+	}
+	switch pc.OnError {
+	case "fail", "ignore", "null":
+	default:
+		slog.Error("unsupported on_error", "on_error", pc.OnError)
+		return ErrInvalidParams
+	}
+	pc.branches = map[string]*Pipeline{}
+	var errs []error
+	for name, configs := range pc.Branches {
+		if pc.branches[name], err = Compile(configs); err != nil {
+			errs = append(errs, fmt.Errorf("branch %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (pc *ParallelConfig) OutputContentType(input string) string {
+	return "application/json"
+}
+
+func (pc *ParallelConfig) subPipelines() []*Pipeline {
+	return slices.Collect(maps.Values(pc.branches))
+}
+
+func (pc *ParallelConfig) Process(data Data) (Data, error) {
+	return pc.ProcessContext(context.Background(), data)
+}
+
+func (pc *ParallelConfig) ProcessContext(ctx context.Context, data Data) (Data, error) {
+	res := Data{ContentType: "application/json"}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := map[string]any{}
+	var firstErr error
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, pipeline := range pc.branches {
+		wg.Go(func() {
+			out, err := pipeline.Process(WithVars(ctx, nil), data)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				slog.Error("parallel branch failed", "branch", name, "error", err)
+				switch pc.OnError {
+				case "null":
+					results[name] = nil
+				case "fail":
+					if firstErr == nil {
+						firstErr = fmt.Errorf("parallel branch %s: %w", name, err)
+						cancel()
+					}
+				}
+				return
+			}
+			results[name] = out.Data
+		})
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return res, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return res, err
+	}
+	res.Data = results
+	return res, nil
+}
+
+func (pc *ParallelConfig) Post(config Config, data Data) error {
+	return nil
+}
+
+func init() {
+	RegisterFilter(&ParallelConfig{})
+}
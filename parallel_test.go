@@ -0,0 +1,93 @@
+package filterweb
+
+import (
+	"testing"
+)
+
+func TestParallel_PrepAndProcess(t *testing.T) {
+	pc := &ParallelConfig{}
+	cfg := Config{Params: map[string]any{
+		"Branches": map[string]any{
+			"double": []map[string]any{{"Name": "jq", "Params": map[string]any{"Expression": ".value * 2"}}},
+			"text": []map[string]any{
+				{"Name": "template", "Params": map[string]any{"Content": "{{.value}} {{.unit}}", "Vars": map[string]any{"unit": "kg"}}},
+			},
+		},
+	}}
+	if err := pc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	out, err := pc.Process(Data{ContentType: "application/json", Data: map[string]any{"value": 21}})
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	res, ok := out.Data.(map[string]any)
+	if !ok {
+		t.Fatalf("unexpected output: %#v", out.Data)
+	}
+	if arr, ok := res["double"].([]any); !ok || arr[0] != 42 {
+		t.Fatalf("unexpected double: %#v", res["double"])
+	}
+	if res["text"] != "21 kg" {
+		t.Fatalf("unexpected text: %#v", res["text"])
+	}
+}
+
+func parallelWithError(t *testing.T, onError string) (Data, error) {
+	t.Helper()
+	pc := &ParallelConfig{}
+	cfg := Config{Params: map[string]any{
+		"OnError": onError,
+		"Branches": map[string]any{
+			"ok":  []map[string]any{{"Name": "jq", "Params": map[string]any{"Expression": "1"}}},
+			"bad": []map[string]any{{"Name": "jq", "Params": map[string]any{"Expression": "error(\"bad\")"}}},
+		},
+	}}
+	if err := pc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	return pc.Process(Data{ContentType: "application/json", Data: nil})
+}
+
+func TestParallel_Process_Fail(t *testing.T) {
+	if _, err := parallelWithError(t, "fail"); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestParallel_Process_Ignore(t *testing.T) {
+	out, err := parallelWithError(t, "ignore")
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	res := out.Data.(map[string]any)
+	if _, ok := res["bad"]; ok || len(res) != 1 {
+		t.Fatalf("unexpected output: %#v", res)
+	}
+}
+
+func TestParallel_Process_Null(t *testing.T) {
+	out, err := parallelWithError(t, "null")
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	res := out.Data.(map[string]any)
+	if v, ok := res["bad"]; !ok || v != nil || len(res) != 2 {
+		t.Fatalf("unexpected output: %#v", res)
+	}
+}
+
+func TestParallel_Prep_InvalidParams(t *testing.T) {
+	pc := &ParallelConfig{}
+	if err := pc.Prep(Config{Params: map[string]any{}}, Data{}); err != ErrMissingParams {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pc = &ParallelConfig{}
+	cfg := Config{Params: map[string]any{
+		"OnError":  "retry",
+		"Branches": map[string]any{"a": []map[string]any{{"Name": "jq"}}},
+	}}
+	if err := pc.Prep(cfg, Data{}); err != ErrInvalidParams {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
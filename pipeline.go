@@ -0,0 +1,316 @@
+package filterweb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+type step struct {
+	config Config
+	filter Filter
+}
+
+// Pipeline is a list of prepared filters. it is compiled once and safe for concurrent use.
+type Pipeline struct {
+	steps []step
+}
+
+// varFilter is a filter which defines or refers variables
+type varFilter interface {
+	definedVars() []string
+	usedVars() []string
+}
+
+// parentFilter is a filter which has sub-pipelines
+type parentFilter interface {
+	subPipelines() []*Pipeline
+}
+
+// Compile prepares filters and checks content types between filters.
+// all problems are reported as FilterError joined.
+func Compile(configs []Config) (*Pipeline, error) {
+	res := &Pipeline{}
+	var errs []error
+	contentType := "" // unknown
+	for i, config := range configs {
+		filter, err := GetFilter(config.Name)
+		if err != nil {
+			slog.Error("filter not found", "filter", config.Name, "index", i)
+			errs = append(errs, &FilterError{Filter: config.Name, Index: i, Err: err})
+			contentType = ""
+			continue
+		}
+		if err = filter.Prep(config, Data{}); err != nil {
+			errs = append(errs, &FilterError{Filter: config.Name, Index: i, Err: err})
+			contentType = ""
+			continue
+		}
+		if contentType != "" && !accepts(filter, contentType) {
+			slog.Error("filter does not accept content type", "filter", config.Name, "index", i, "contenttype", contentType)
+			err = fmt.Errorf("%w: %s does not accept %s", ErrContentTypeMismatch, config.Name, contentType)
+			errs = append(errs, &FilterError{Filter: config.Name, Index: i, Err: err})
+		}
+		contentType = outputContentType(filter, contentType)
+		res.steps = append(res.steps, step{config: config, filter: filter})
+	}
+	if len(errs) != 0 {
+		return nil, errors.Join(errs...)
+	}
+	return res, nil
+}
+
+// accepts checks content type is acceptable by filter
+func accepts(filter Filter, contentType string) bool {
+	accepts := filter.Accepts()
+	return len(accepts) == 0 || slices.Contains(accepts, contentType) || slices.Contains(accepts, "*")
+}
+
+// outputContentType returns output content type of filter, "" for unknown
+func outputContentType(filter Filter, input string) string {
+	if of, ok := filter.(OutputFilter); ok {
+		return of.OutputContentType(input)
+	}
+	return ""
+}
+
+// OutputContentType returns output content type of the pipeline, "" for unknown
+func (p *Pipeline) OutputContentType(input string) string {
+	for _, s := range p.steps {
+		input = outputContentType(s.filter, input)
+	}
+	return input
+}
+
+// commonContentType returns output content type if all pipelines have the same one
+func commonContentType(input string, pipelines ...*Pipeline) string {
+	res := ""
+	for i, p := range pipelines {
+		ct := p.OutputContentType(input)
+		if i == 0 {
+			res = ct
+		} else if ct != res {
+			return ""
+		}
+	}
+	return res
+}
+
+// Calls returns pipeline names called in the pipeline and its sub-pipelines
+func (p *Pipeline) Calls() []string {
+	var res []string
+	for _, s := range p.steps {
+		if cc, ok := s.filter.(*CallConfig); ok {
+			res = append(res, cc.Pipeline)
+		}
+		if pf, ok := s.filter.(parentFilter); ok {
+			for _, sub := range pf.subPipelines() {
+				res = append(res, sub.Calls()...)
+			}
+		}
+	}
+	return res
+}
+
+// variables returns variables defined and referenced in the pipeline and its sub-pipelines
+func (p *Pipeline) variables() (defined, used []string) {
+	for _, s := range p.steps {
+		if vf, ok := s.filter.(varFilter); ok {
+			defined = append(defined, vf.definedVars()...)
+			used = append(used, vf.usedVars()...)
+		}
+		if pf, ok := s.filter.(parentFilter); ok {
+			for _, sub := range pf.subPipelines() {
+				d, u := sub.variables()
+				defined = append(defined, d...)
+				used = append(used, u...)
+			}
+		}
+	}
+	return
+}
+
+// Process runs filters with initial data, stops when ctx is done
+func (p *Pipeline) Process(ctx context.Context, data Data) (Data, error) {
+	if VarsFromContext(ctx) == nil {
+		ctx = WithVars(ctx, nil)
+	}
+	for i, s := range p.steps {
+		config, filter := s.config, s.filter
+		if err := ctx.Err(); err != nil {
+			slog.Error("filters aborted", "filter", config.Name, "error", err)
+			return data, err
+		}
+		slog.Debug("processing filter", "config", config, "data", data)
+		if !accepts(filter, data.ContentType) {
+			slog.Error("filter does not accept content type", "filter", filter.Name(), "data", data)
+			return data, &FilterError{Filter: config.Name, Index: i, Err: ErrContentTypeMismatch}
+		}
+		slog.Debug("Process", "name", filter.Name(), "filter", filter, "data", data)
+		response := data.Response
+		var err error
+		if cf, ok := filter.(ContextFilter); ok {
+			data, err = cf.ProcessContext(ctx, data)
+		} else {
+			data, err = filter.Process(data)
+		}
+		if err != nil {
+			return data, &FilterError{Filter: config.Name, Index: i, Err: err}
+		}
+		if data.Response == nil {
+			data.Response = response
+		}
+		slog.Debug("Post", "name", filter.Name(), "filter", filter, "data", data)
+		err = filter.Post(config, data)
+		if err != nil {
+			return data, &FilterError{Filter: config.Name, Index: i, Err: err}
+		}
+	}
+	return data, nil
+}
+
+// CompiledRoute is a route with compiled filters
+type CompiledRoute struct {
+	ConfigSchema
+	Pipeline *Pipeline
+	cache    *Cache
+}
+
+// Process runs filters of the route with request data, using cache if enabled
+func (r *CompiledRoute) Process(ctx context.Context, data Data) (Data, error) {
+	if r.cache == nil {
+		return r.Pipeline.Process(ctx, data)
+	}
+	key, ok := r.cacheKey(data)
+	if !ok {
+		return r.Pipeline.Process(ctx, data)
+	}
+	return r.cache.Do(ctx, key, r.Cache.TTL, r.Cache.Stale, func(ctx context.Context) (Data, error) {
+		return r.Pipeline.Process(ctx, data)
+	})
+}
+
+// cacheKey makes cache key of request data from method, path, query and headers of the route.
+// false if the request should not be cached.
+func (r *CompiledRoute) cacheKey(data Data) (string, bool) {
+	req, ok := data.Data.(map[string]any)
+	if !ok {
+		return "", false
+	}
+	method, _ := req["method"].(string)
+	if method != http.MethodGet && method != http.MethodHead {
+		return "", false
+	}
+	path, _ := req["path"].(string)
+	rawquery, _ := req["raw_query"].(string)
+	if query, err := url.ParseQuery(rawquery); err == nil {
+		// ignore order of parameters
+		rawquery = query.Encode()
+	}
+	key := []string{method, path, rawquery}
+	headers, _ := req["headers"].(map[string]any)
+	for _, name := range r.Cache.Headers {
+		name = strings.ToLower(name)
+		key = append(key, fmt.Sprintf("%s: %v", name, headers[name]))
+	}
+	return strings.Join(key, "\n"), true
+}
+
+// CompiledConfig is a config file with compiled routes and pipelines
+type CompiledConfig struct {
+	Routes    []CompiledRoute
+	Pipelines map[string]*Pipeline
+}
+
+// Compile compiles all routes and pipelines, and checks called pipelines exist.
+// all problems are reported with its route or pipeline.
+func (cf *ConfigFile) Compile() (*CompiledConfig, error) {
+	res := &CompiledConfig{Pipelines: map[string]*Pipeline{}}
+	var errs []error
+	calls := map[string][]string{} // label -> called pipelines
+	used := map[string][]string{}  // label -> referenced variables
+	defined := map[string]bool{"request": true}
+	addVars := func(label string, p *Pipeline) {
+		d, u := p.variables()
+		for _, name := range d {
+			defined[name] = true
+		}
+		used[label] = u
+	}
+	for _, name := range slices.Sorted(maps.Keys(cf.Pipelines)) {
+		configs := cf.Pipelines[name]
+		label := "pipeline " + name
+		p, err := Compile(configs)
+		if err != nil {
+			errs = append(errs, withLabel(label, err)...)
+			continue
+		}
+		res.Pipelines[name] = p
+		calls[label] = p.Calls()
+		addVars(label, p)
+	}
+	for _, route := range cf.Routes {
+		label := "route " + route.Path
+		if route.Method != "" {
+			label = fmt.Sprintf("route %s %s", route.Method, route.Path)
+		}
+		p, err := Compile(route.Filters)
+		if err != nil {
+			errs = append(errs, withLabel(label, err)...)
+			continue
+		}
+		cr := CompiledRoute{ConfigSchema: route, Pipeline: p}
+		if route.Cache != nil {
+			if route.Cache.TTL <= 0 || route.Cache.Stale < 0 {
+				errs = append(errs, fmt.Errorf("%s: %w: cache requires positive ttl", label, ErrInvalidParams))
+				continue
+			}
+			maxEntries := route.Cache.MaxEntries
+			if maxEntries == 0 {
+				maxEntries = 1000
+			}
+			cr.cache = NewCache(maxEntries, route.Cache.MaxBytes)
+		}
+		res.Routes = append(res.Routes, cr)
+		calls[label] = p.Calls()
+		addVars(label, p)
+	}
+	for _, label := range slices.Sorted(maps.Keys(calls)) {
+		for _, name := range calls[label] {
+			if _, ok := cf.Pipelines[name]; !ok {
+				errs = append(errs, fmt.Errorf("%s: %w: %s", label, ErrPipelineNotFound, name))
+			}
+		}
+	}
+	// variables are visible in called pipelines, so any definition in the config is accepted
+	for _, label := range slices.Sorted(maps.Keys(used)) {
+		for _, name := range used[label] {
+			if !defined[name] {
+				slog.Error("unknown variable", "label", label, "variable", name)
+				errs = append(errs, fmt.Errorf("%s: %w: $%s", label, ErrUnknownVariable, name))
+			}
+		}
+	}
+	if len(errs) != 0 {
+		return nil, errors.Join(errs...)
+	}
+	return res, nil
+}
+
+// withLabel prefixes each of joined errors with label
+func withLabel(label string, err error) []error {
+	var res []error
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			res = append(res, fmt.Errorf("%s: %w", label, e))
+		}
+		return res
+	}
+	return []error{fmt.Errorf("%s: %w", label, err)}
+}
@@ -0,0 +1,210 @@
+package filterweb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCompile_CollectErrors(t *testing.T) {
+	_, err := Compile([]Config{
+		{Name: "nonexistent"},
+		{Name: "jq", Params: map[string]any{}},
+		{Name: "jq", Params: map[string]any{"Expression": "."}},
+	})
+	if !errors.Is(err, ErrFilterNotFound) || !errors.Is(err, ErrMissingParams) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var fe *FilterError
+	if !errors.As(err, &fe) || fe.Filter != "nonexistent" || fe.Index != 0 {
+		t.Fatalf("unexpected filter error: %#v", fe)
+	}
+	if !strings.Contains(err.Error(), "jq[1]") {
+		t.Fatalf("index of second error is missing: %v", err)
+	}
+}
+
+func TestCompile_ContentTypeMismatch(t *testing.T) {
+	_, err := Compile([]Config{
+		{Name: "constant", Params: map[string]any{"Data": "hello"}},
+		{Name: "template", Params: map[string]any{"Content": "{{.}}"}},
+	})
+	if !errors.Is(err, ErrContentTypeMismatch) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestCompile_OutputContentType(t *testing.T) {
+	p, err := Compile([]Config{
+		{Name: "constant", Params: map[string]any{"ContentType": "application/json", "Data": `{"a":1}`}},
+		{Name: "response", Params: map[string]any{"Status": 201}},
+		{Name: "switch", Params: map[string]any{"Cases": []map[string]any{
+			{"When": ".a == 1", "Filters": []map[string]any{{"Name": "jq", "Params": map[string]any{"Expression": ".a"}}}},
+		}}},
+		{Name: "template", Params: map[string]any{"Content": "{{.}}"}},
+	})
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if ct := p.OutputContentType(""); ct != "text/plain" {
+		t.Fatalf("unexpected content type: %v", ct)
+	}
+}
+
+func TestConfigFile_Compile_PipelineNotFound(t *testing.T) {
+	cf := &ConfigFile{
+		Routes: []ConfigSchema{{Method: "GET", Path: "/", Filters: []Config{
+			{Name: "try", Params: map[string]any{"Filters": []map[string]any{
+				{"Name": "call", "Params": map[string]any{"Pipeline": "missing"}},
+			}}},
+		}}},
+	}
+	_, err := cf.Compile()
+	if !errors.Is(err, ErrPipelineNotFound) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(err.Error(), "route GET /") {
+		t.Fatalf("route is missing: %v", err)
+	}
+}
+
+func TestConfigFile_Compile_UnknownVariable(t *testing.T) {
+	cf := &ConfigFile{
+		Pipelines: map[string][]Config{
+			"p": {{Name: "jq", Params: map[string]any{"Expression": "[$id, $arg, $request]"}}},
+		},
+		Routes: []ConfigSchema{{Path: "/ok", Filters: []Config{
+			{Name: "set", Params: map[string]any{"Var": "id", "Expression": "$request.path"}},
+			{Name: "call", Params: map[string]any{"Pipeline": "p", "Args": map[string]any{"arg": 1}}},
+			{Name: "jq", Params: map[string]any{"Expression": `[. as $x | $x, "$notvar", $id]`}},
+		}}},
+	}
+	if _, err := cf.Compile(); err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	cf.Routes = append(cf.Routes, ConfigSchema{Path: "/typo", Filters: []Config{
+		{Name: "switch", Params: map[string]any{"Cases": []map[string]any{
+			{"When": "$typo", "Filters": []map[string]any{{"Name": "jq", "Params": map[string]any{"Expression": "$id2"}}}},
+		}}},
+	}})
+	_, err := cf.Compile()
+	if !errors.Is(err, ErrUnknownVariable) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, s := range []string{"route /typo: unknown variable: $typo", "route /typo: unknown variable: $id2"} {
+		if !strings.Contains(err.Error(), s) {
+			t.Fatalf("%q is not reported: %v", s, err)
+		}
+	}
+}
+
+func TestPipeline_Process_Concurrent(t *testing.T) {
+	p, err := Compile([]Config{
+		{Name: "set", Params: map[string]any{"Var": "n", "Expression": ".n"}},
+		{Name: "template", Params: map[string]any{"Content": `{{var "n"}}`}},
+	})
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Go(func() {
+			out, err := p.Process(context.Background(), Data{ContentType: "application/json", Data: map[string]any{"n": i}})
+			if err != nil {
+				t.Errorf("Process failed: %v", err)
+				return
+			}
+			if out.String() != fmt.Sprint(i) {
+				t.Errorf("unexpected output: %q != %d", out.String(), i)
+			}
+		})
+	}
+	wg.Wait()
+}
+
+func TestCompiledRoute_Process_Cache(t *testing.T) {
+	cf := &ConfigFile{Routes: []ConfigSchema{{
+		Path:    "/now",
+		Filters: []Config{{Name: "jq", Params: map[string]any{"Expression": "now"}}},
+		Cache:   &RouteCache{TTL: time.Minute, Headers: []string{"Accept"}},
+	}}}
+	compiled, err := cf.Compile()
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	route := compiled.Routes[0]
+	request := func(method, query, accept string) string {
+		out, err := route.Process(context.Background(), Data{ContentType: "application/json", Data: map[string]any{
+			"method": method, "path": "/now", "raw_query": query,
+			"headers": map[string]any{"accept": accept},
+		}})
+		if err != nil {
+			t.Fatalf("Process failed: %v", err)
+		}
+		return out.String()
+	}
+	first := request("GET", "a=1&b=2", "text/plain")
+	time.Sleep(time.Millisecond)
+	if v := request("GET", "b=2&a=1", "text/plain"); v != first {
+		t.Fatalf("not cached: %v != %v", v, first)
+	}
+	if v := request("GET", "a=1&b=2", "application/json"); v == first {
+		t.Fatalf("header is not in the key: %v", v)
+	}
+	if v := request("POST", "a=1&b=2", "text/plain"); v == first {
+		t.Fatalf("POST is cached: %v", v)
+	}
+}
+
+func TestCompiledRoute_Process_CachePrivate(t *testing.T) {
+	for name, params := range map[string]map[string]any{
+		"cookie": {"Cookies": []map[string]any{{"Name": "session", "Value": "{{.}}"}}},
+		"status": {"Status": 404},
+	} {
+		cf := &ConfigFile{Routes: []ConfigSchema{{
+			Path: "/now",
+			Filters: []Config{
+				{Name: "jq", Params: map[string]any{"Expression": "now | tostring"}},
+				{Name: "response", Params: params},
+			},
+			Cache: &RouteCache{TTL: time.Minute},
+		}}}
+		compiled, err := cf.Compile()
+		if err != nil {
+			t.Fatalf("%s: Compile failed: %v", name, err)
+		}
+		route := compiled.Routes[0]
+		request := func() string {
+			out, err := route.Process(context.Background(), Data{ContentType: "application/json", Data: map[string]any{
+				"method": "GET", "path": "/now",
+			}})
+			if err != nil {
+				t.Fatalf("%s: Process failed: %v", name, err)
+			}
+			return out.String()
+		}
+		first := request()
+		time.Sleep(time.Millisecond)
+		if v := request(); v == first {
+			t.Fatalf("%s: private response is cached: %v", name, v)
+		}
+		if route.cache.Len() != 0 {
+			t.Fatalf("%s: unexpected cache length: %d", name, route.cache.Len())
+		}
+	}
+}
+
+func TestConfigFile_Compile_InvalidCache(t *testing.T) {
+	cf := &ConfigFile{Routes: []ConfigSchema{{
+		Path:    "/",
+		Filters: []Config{{Name: "jq", Params: map[string]any{"Expression": "."}}},
+		Cache:   &RouteCache{},
+	}}}
+	if _, err := cf.Compile(); !errors.Is(err, ErrInvalidParams) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package filterweb
+
+import (
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// RequestData converts an incoming HTTP request to the initial data of the pipeline.
+//
+// query values are string when single-valued, array when repeated.
+// header names are lowercased and repeated values are joined with ", ".
+func RequestData(r *http.Request) (Data, error) {
+	res := Data{ContentType: "application/json"}
+	query := map[string]any{}
+	for k, v := range r.URL.Query() {
+		if len(v) == 1 {
+			query[k] = v[0]
+		} else {
+			vals := []any{}
+			for _, s := range v {
+				vals = append(vals, s)
+			}
+			query[k] = vals
+		}
+	}
+	headers := map[string]any{}
+	for k, v := range r.Header {
+		headers[strings.ToLower(k)] = strings.Join(v, ", ")
+	}
+	cookies := map[string]any{}
+	for _, c := range r.Cookies() {
+		cookies[c.Name] = c.Value
+	}
+	params := map[string]any{}
+	for _, name := range patternParams(r.Pattern) {
+		params[name] = r.PathValue(name)
+	}
+	reqdata := map[string]any{
+		"method":    r.Method,
+		"path":      r.URL.Path,
+		"host":      r.Host,
+		"raw_query": r.URL.RawQuery,
+		"query":     query,
+		"params":    params,
+		"headers":   headers,
+		"cookies":   cookies,
+		"remote":    r.RemoteAddr,
+		"body":      nil,
+	}
+	if r.Body != nil {
+		buf, err := io.ReadAll(r.Body)
+		if err != nil {
+			slog.Error("read request body", "error", err)
+			return res, err
+		}
+		if len(buf) != 0 {
+			mediaType := ""
+			if ct := r.Header.Get("Content-Type"); ct != "" {
+				mediaType, _, err = mime.ParseMediaType(ct)
+				if err != nil {
+					slog.Error("parse content type", "content-type", ct, "error", err)
+					return res, ErrDecode
+				}
+			}
+			reqdata["content_type"] = mediaType
+			body, err := DecodeContentType(mediaType, buf)
+			if err != nil {
+				slog.Error("decode request body", "contenttype", mediaType, "error", err)
+				return res, ErrDecode
+			}
+			reqdata["body"] = normalize(body)
+		}
+	}
+	res.Data = reqdata
+	return res, nil
+}
+
+var wildcard = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// patternParams returns wildcard names in ServeMux pattern
+func patternParams(pattern string) []string {
+	var names []string
+	for _, m := range wildcard.FindAllStringSubmatch(pattern, -1) {
+		name := strings.TrimSuffix(m[1], "...")
+		if name != "" && name != "$" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// normalize converts decoded values to types that jq can handle
+func normalize(v any) any {
+	switch val := v.(type) {
+	case []byte:
+		return string(val)
+	case map[string]string:
+		res := map[string]any{}
+		for k, s := range val {
+			res[k] = s
+		}
+		return res
+	case []map[string]any:
+		res := []any{}
+		for _, m := range val {
+			res = append(res, m)
+		}
+		return res
+	}
+	return v
+}
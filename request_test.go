@@ -0,0 +1,108 @@
+package filterweb
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestData_QueryHeadersCookies(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/users?id=1&tag=a&tag=b", nil)
+	req.Header.Set("X-Token", "secret")
+	req.AddCookie(&http.Cookie{Name: "session", Value: "abc"})
+	out, err := RequestData(req)
+	if err != nil {
+		t.Fatalf("RequestData failed: %v", err)
+	}
+	if out.ContentType != "application/json" {
+		t.Fatalf("unexpected content type: %v", out.ContentType)
+	}
+	m, ok := out.Data.(map[string]any)
+	if !ok {
+		t.Fatalf("unexpected data type: %T", out.Data)
+	}
+	if m["method"] != "GET" || m["path"] != "/users" {
+		t.Fatalf("unexpected method/path: %v %v", m["method"], m["path"])
+	}
+	query := m["query"].(map[string]any)
+	if query["id"] != "1" {
+		t.Fatalf("unexpected query id: %#v", query["id"])
+	}
+	if tags, ok := query["tag"].([]any); !ok || len(tags) != 2 || tags[1] != "b" {
+		t.Fatalf("unexpected query tag: %#v", query["tag"])
+	}
+	if m["headers"].(map[string]any)["x-token"] != "secret" {
+		t.Fatalf("unexpected headers: %#v", m["headers"])
+	}
+	if m["cookies"].(map[string]any)["session"] != "abc" {
+		t.Fatalf("unexpected cookies: %#v", m["cookies"])
+	}
+	if m["body"] != nil {
+		t.Fatalf("unexpected body: %#v", m["body"])
+	}
+}
+
+func TestRequestData_JSONBody(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"Alice"}`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	out, err := RequestData(req)
+	if err != nil {
+		t.Fatalf("RequestData failed: %v", err)
+	}
+	m := out.Data.(map[string]any)
+	body, ok := m["body"].(map[string]any)
+	if !ok {
+		t.Fatalf("unexpected body type: %T", m["body"])
+	}
+	if body["name"] != "Alice" {
+		t.Fatalf("unexpected body: %#v", body)
+	}
+}
+
+func TestRequestData_InvalidBody(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{invalid`))
+	req.Header.Set("Content-Type", "application/json")
+	_, err := RequestData(req)
+	if err != ErrDecode {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestProcessFiltersData_Jq(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/hello?name=Bob", nil)
+	in, err := RequestData(req)
+	if err != nil {
+		t.Fatalf("RequestData failed: %v", err)
+	}
+	out, err := ProcessFiltersData([]Config{
+		{Name: "jq", Params: map[string]any{"Expression": ".query.name"}},
+	}, in)
+	if err != nil {
+		t.Fatalf("ProcessFiltersData failed: %v", err)
+	}
+	res, ok := out.Data.([]any)
+	if !ok || len(res) != 1 || res[0] != "Bob" {
+		t.Fatalf("unexpected output: %#v", out.Data)
+	}
+}
+
+func TestRequestData_PathParams(t *testing.T) {
+	var out Data
+	var err error
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}/files/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		out, err = RequestData(r)
+	})
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/123/files/a/b.txt", nil))
+	if err != nil {
+		t.Fatalf("RequestData failed: %v", err)
+	}
+	params, ok := out.Data.(map[string]any)["params"].(map[string]any)
+	if !ok {
+		t.Fatalf("unexpected data: %#v", out.Data)
+	}
+	if params["id"] != "123" || params["path"] != "a/b.txt" {
+		t.Fatalf("unexpected params: %#v", params)
+	}
+}
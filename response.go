@@ -0,0 +1,163 @@
+package filterweb
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	tmplText "text/template"
+)
+
+type CookieConfig struct {
+	Name     string // cookie name
+	Value    string // cookie value (template)
+	Path     string
+	Domain   string
+	MaxAge   int
+	Secure   bool
+	HttpOnly bool
+	SameSite string // lax, strict or none
+}
+
+type ResponseConfig struct {
+	Filter
+	Status         int               // status code
+	StatusTemplate string            // status code (template), overrides Status
+	Location       string            // redirect location (template)
+	Headers        map[string]string // response headers (template)
+	Cookies        []CookieConfig    // response cookies
+	tmplstatus     *tmplText.Template
+	tmpllocation   *tmplText.Template
+	tmplheaders    map[string]*tmplText.Template
+	tmplcookies    []*tmplText.Template
+}
+
+func (rc *ResponseConfig) New() Filter {
+	return &ResponseConfig{}
+}
+
+func (rc *ResponseConfig) Name() string {
+	return "response"
+}
+
+func (rc *ResponseConfig) Accepts() []string {
+	return []string{}
+}
+
+func (rc *ResponseConfig) OutputContentType(input string) string {
+	return input
+}
+
+func (rc *ResponseConfig) Prep(config Config, data Data) (err error) {
+	if err = decodeParams(config.Params, rc); err != nil {
+		slog.Error("mapstructure decode", "type", rc.Name(), "params", config.Params)
+		return err
+	}
+	if rc.StatusTemplate != "" {
+		if rc.tmplstatus, err = parseText("status", rc.StatusTemplate); err != nil {
+			slog.Error("status template parse error", "template", rc.StatusTemplate, "error", err)
+			return err
+		}
+	}
+	if rc.Location != "" {
+		if rc.tmpllocation, err = parseText("location", rc.Location); err != nil {
+			slog.Error("location template parse error", "template", rc.Location, "error", err)
+			return err
+		}
+	}
+	rc.tmplheaders = map[string]*tmplText.Template{}
+	for k, v := range rc.Headers {
+		if rc.tmplheaders[k], err = parseText(k, v); err != nil {
+			slog.Error("header template parse error", "header", k, "template", v, "error", err)
+			return err
+		}
+	}
+	rc.tmplcookies = nil
+	for _, c := range rc.Cookies {
+		if c.Name == "" {
+			slog.Error("response cookie requires 'name' parameter")
+			return ErrMissingParams
+		}
+		tmpl, err := parseText(c.Name, c.Value)
+		if err != nil {
+			slog.Error("cookie template parse error", "cookie", c.Name, "template", c.Value, "error", err)
+			return err
+		}
+		rc.tmplcookies = append(rc.tmplcookies, tmpl)
+	}
+	return nil
+}
+
+func (rc *ResponseConfig) Process(data Data) (Data, error) {
+	return rc.ProcessContext(context.Background(), data)
+}
+
+func (rc *ResponseConfig) ProcessContext(ctx context.Context, data Data) (Data, error) {
+	resp := &Response{Status: rc.Status, Headers: http.Header{}}
+	if data.Response != nil {
+		if data.Response.Status != 0 && resp.Status == 0 {
+			resp.Status = data.Response.Status
+		}
+		if data.Response.Headers != nil {
+			resp.Headers = data.Response.Headers.Clone()
+		}
+		resp.Cookies = append(resp.Cookies, data.Response.Cookies...)
+	}
+	if rc.tmplstatus != nil {
+		val, err := renderText(ctx, rc.tmplstatus, data.Data)
+		if err != nil {
+			return data, err
+		}
+		if resp.Status, err = strconv.Atoi(strings.TrimSpace(val)); err != nil {
+			slog.Error("invalid status code", "status", val, "error", err)
+			return data, err
+		}
+	}
+	if rc.tmpllocation != nil {
+		val, err := renderText(ctx, rc.tmpllocation, data.Data)
+		if err != nil {
+			return data, err
+		}
+		resp.Headers.Set("Location", val)
+		if resp.Status == 0 {
+			resp.Status = http.StatusFound
+		}
+	}
+	for k, tmpl := range rc.tmplheaders {
+		val, err := renderText(ctx, tmpl, data.Data)
+		if err != nil {
+			return data, err
+		}
+		resp.Headers.Set(k, val)
+	}
+	for i, c := range rc.Cookies {
+		val, err := renderText(ctx, rc.tmplcookies[i], data.Data)
+		if err != nil {
+			return data, err
+		}
+		cookie := &http.Cookie{
+			Name: c.Name, Value: val, Path: c.Path, Domain: c.Domain,
+			MaxAge: c.MaxAge, Secure: c.Secure, HttpOnly: c.HttpOnly,
+		}
+		switch strings.ToLower(c.SameSite) {
+		case "lax":
+			cookie.SameSite = http.SameSiteLaxMode
+		case "strict":
+			cookie.SameSite = http.SameSiteStrictMode
+		case "none":
+			cookie.SameSite = http.SameSiteNoneMode
+		}
+		resp.Cookies = append(resp.Cookies, cookie)
+	}
+	data.Response = resp
+	return data, nil
+}
+
+func (rc *ResponseConfig) Post(config Config, data Data) error {
+	return nil
+}
+
+func init() {
+	RegisterFilter(&ResponseConfig{})
+}
@@ -0,0 +1,96 @@
+package filterweb
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestResponse_PrepAndProcess(t *testing.T) {
+	rc := &ResponseConfig{}
+	cfg := Config{Params: map[string]any{
+		"Status":  201,
+		"Headers": map[string]string{"Cache-Control": "max-age={{.ttl}}"},
+		"Cookies": []map[string]any{{"Name": "id", "Value": "{{.id}}", "HttpOnly": true, "SameSite": "lax"}},
+	}}
+	if err := rc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	in := map[string]any{"ttl": 60, "id": "abc"}
+	out, err := rc.Process(Data{ContentType: "application/json", Data: in})
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if out.ContentType != "application/json" {
+		t.Fatalf("unexpected content type: %v", out.ContentType)
+	}
+	if out.Response == nil || out.Response.Status != 201 {
+		t.Fatalf("unexpected response: %#v", out.Response)
+	}
+	if v := out.Response.Headers.Get("Cache-Control"); v != "max-age=60" {
+		t.Fatalf("unexpected header: %q", v)
+	}
+	if len(out.Response.Cookies) != 1 {
+		t.Fatalf("unexpected cookies: %#v", out.Response.Cookies)
+	}
+	c := out.Response.Cookies[0]
+	if c.Value != "abc" || !c.HttpOnly || c.SameSite != http.SameSiteLaxMode {
+		t.Fatalf("unexpected cookie: %#v", c)
+	}
+}
+
+func TestResponse_Process_Location(t *testing.T) {
+	rc := &ResponseConfig{}
+	cfg := Config{Params: map[string]any{"Location": "/users/{{.id}}"}}
+	if err := rc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	out, err := rc.Process(Data{Data: map[string]any{"id": 123}})
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if out.Response.Status != http.StatusFound {
+		t.Fatalf("unexpected status: %v", out.Response.Status)
+	}
+	if v := out.Response.Headers.Get("Location"); v != "/users/123" {
+		t.Fatalf("unexpected location: %q", v)
+	}
+}
+
+func TestResponse_Process_StatusTemplate(t *testing.T) {
+	rc := &ResponseConfig{}
+	cfg := Config{Params: map[string]any{"StatusTemplate": "{{if .items}}200{{else}}404{{end}}"}}
+	if err := rc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	out, err := rc.Process(Data{Data: map[string]any{"items": []any{}}})
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if out.Response.Status != http.StatusNotFound {
+		t.Fatalf("unexpected status: %v", out.Response.Status)
+	}
+}
+
+func TestResponse_Prep_MissingCookieName(t *testing.T) {
+	rc := &ResponseConfig{}
+	cfg := Config{Params: map[string]any{"Cookies": []map[string]any{{"Value": "x"}}}}
+	if err := rc.Prep(cfg, Data{}); err != ErrMissingParams {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestProcessFilters_KeepResponse(t *testing.T) {
+	out, err := ProcessFilters([]Config{
+		{Name: "response", Params: map[string]any{"Status": 404}},
+		{Name: "constant", Params: map[string]any{"Data": "not found"}},
+	})
+	if err != nil {
+		t.Fatalf("ProcessFilters failed: %v", err)
+	}
+	if out.Response == nil || out.Response.Status != 404 {
+		t.Fatalf("response is not kept: %#v", out.Response)
+	}
+	if out.String() != "not found" {
+		t.Fatalf("unexpected data: %v", out)
+	}
+}
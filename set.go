@@ -0,0 +1,86 @@
+package filterweb
+
+import (
+	"context"
+	"log/slog"
+)
+
+type SetConfig struct {
+	Filter
+	Var        string // variable name
+	Expression string // jq expression to extract the value, whole data if empty
+	query      *jqQuery
+}
+
+func (sc *SetConfig) New() Filter {
+	return &SetConfig{}
+}
+
+func (sc *SetConfig) Name() string {
+	return "set"
+}
+
+func (sc *SetConfig) Accepts() []string {
+	return []string{}
+}
+
+func (sc *SetConfig) OutputContentType(input string) string {
+	return input
+}
+
+func (sc *SetConfig) Prep(config Config, data Data) (err error) {
+	if err = decodeParams(config.Params, sc); err != nil {
+		slog.Error("mapstructure decode", "type", sc.Name(), "params", config.Params)
+		return err
+	}
+	// mandatory
+	if sc.Var == "" {
+		slog.Error("set filter requires 'var' parameter")
+		return ErrMissingParams
+	}
+	if sc.Expression != "" {
+		if sc.query, err = compileQuery(sc.Expression); err != nil {
+			slog.Error("jq filter parse error", "expr", sc.Expression)
+			return err
+		}
+	}
+	return nil
+}
+
+func (sc *SetConfig) Process(data Data) (Data, error) {
+	return sc.ProcessContext(context.Background(), data)
+}
+
+func (sc *SetConfig) ProcessContext(ctx context.Context, data Data) (Data, error) {
+	vars := VarsFromContext(ctx)
+	if vars == nil {
+		slog.Error("no variable store", "var", sc.Var)
+		return data, ErrInvalidData
+	}
+	value := normalize(data.Data)
+	if sc.query != nil {
+		outs, err := runQuery(ctx, sc.query, data.Data)
+		if err != nil {
+			return data, err
+		}
+		if len(outs) == 1 {
+			value = outs[0]
+		} else {
+			value = outs
+		}
+	}
+	slog.Debug("set variable", "var", sc.Var, "value", value)
+	vars.Set(sc.Var, value)
+	return data, nil
+}
+
+func (sc *SetConfig) definedVars() []string { return []string{sc.Var} }
+func (sc *SetConfig) usedVars() []string    { return usedVars(sc.query) }
+
+func (sc *SetConfig) Post(config Config, data Data) error {
+	return nil
+}
+
+func init() {
+	RegisterFilter(&SetConfig{})
+}
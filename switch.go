@@ -0,0 +1,125 @@
+package filterweb
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+type SwitchCase struct {
+	When     string   // jq expression, matches when first output is truthy
+	Filters  []Config // filters to process when matched
+	query    *jqQuery
+	pipeline *Pipeline
+}
+
+type SwitchConfig struct {
+	Filter
+	Cases    []SwitchCase // evaluated in order
+	Default  []Config     // filters to process when no case matched
+	pipeline *Pipeline    // default
+}
+
+func (sc *SwitchConfig) New() Filter {
+	return &SwitchConfig{}
+}
+
+func (sc *SwitchConfig) Name() string {
+	return "switch"
+}
+
+func (sc *SwitchConfig) Accepts() []string {
+	return []string{}
+}
+
+func (sc *SwitchConfig) Prep(config Config, data Data) (err error) {
+	if err = decodeParams(config.Params, sc); err != nil {
+		slog.Error("mapstructure decode", "type", sc.Name(), "params", config.Params)
+		return err
+	}
+	// mandatory
+	if len(sc.Cases) == 0 {
+		slog.Error("switch filter requires 'cases' parameter")
+		return ErrMissingParams
+	}
+	for i := range sc.Cases {
+		c := &sc.Cases[i]
+		if c.When == "" {
+			slog.Error("switch case requires 'when' parameter", "case", i)
+			return ErrMissingParams
+		}
+		if c.query, err = compileQuery(c.When); err != nil {
+			slog.Error("jq parse error", "case", i, "expr", c.When)
+			return err
+		}
+		if c.pipeline, err = Compile(c.Filters); err != nil {
+			return fmt.Errorf("case %d: %w", i, err)
+		}
+	}
+	if sc.Default != nil {
+		if sc.pipeline, err = Compile(sc.Default); err != nil {
+			return fmt.Errorf("default: %w", err)
+		}
+	}
+	return nil
+}
+
+func (sc *SwitchConfig) OutputContentType(input string) string {
+	pipelines := sc.subPipelines()
+	if sc.pipeline == nil {
+		// pass through
+		pipelines = append(pipelines, &Pipeline{})
+	}
+	return commonContentType(input, pipelines...)
+}
+
+func (sc *SwitchConfig) subPipelines() []*Pipeline {
+	var res []*Pipeline
+	for _, c := range sc.Cases {
+		res = append(res, c.pipeline)
+	}
+	if sc.pipeline != nil {
+		res = append(res, sc.pipeline)
+	}
+	return res
+}
+
+func (sc *SwitchConfig) Process(data Data) (Data, error) {
+	return sc.ProcessContext(context.Background(), data)
+}
+
+func (sc *SwitchConfig) ProcessContext(ctx context.Context, data Data) (Data, error) {
+	for i, c := range sc.Cases {
+		outs, err := runQuery(ctx, c.query, data.Data)
+		if err != nil {
+			return data, err
+		}
+		if len(outs) != 0 && outs[0] != nil && outs[0] != false {
+			slog.Debug("switch case matched", "case", i, "when", c.When)
+			return c.pipeline.Process(ctx, data)
+		}
+	}
+	if sc.pipeline != nil {
+		slog.Debug("switch default")
+		return sc.pipeline.Process(ctx, data)
+	}
+	return data, nil
+}
+
+func (sc *SwitchConfig) definedVars() []string { return nil }
+
+func (sc *SwitchConfig) usedVars() []string {
+	var res []string
+	for _, c := range sc.Cases {
+		res = append(res, usedVars(c.query)...)
+	}
+	return res
+}
+
+func (sc *SwitchConfig) Post(config Config, data Data) error {
+	return nil
+}
+
+func init() {
+	RegisterFilter(&SwitchConfig{})
+}
@@ -0,0 +1,78 @@
+package filterweb
+
+import (
+	"testing"
+)
+
+func switchFilter(t *testing.T) *SwitchConfig {
+	t.Helper()
+	sc := &SwitchConfig{}
+	cfg := Config{Params: map[string]any{
+		"Cases": []map[string]any{
+			{
+				"When":    "length == 0",
+				"Filters": []map[string]any{{"Name": "constant", "Params": map[string]any{"Data": "empty"}}},
+			},
+			{
+				"When":    ".[0] == \"x\"",
+				"Filters": []map[string]any{{"Name": "constant", "Params": map[string]any{"Data": "x"}}},
+			},
+		},
+		"Default": []map[string]any{
+			{"Name": "template", "Params": map[string]any{"Content": "{{range .}}{{.}}{{end}}"}},
+		},
+	}}
+	if err := sc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	return sc
+}
+
+func TestSwitch_Process(t *testing.T) {
+	cases := []struct {
+		input    []any
+		expected string
+	}{
+		{[]any{}, "empty"},
+		{[]any{"x", "y"}, "x"},
+		{[]any{"a", "b"}, "ab"},
+	}
+	for _, c := range cases {
+		out, err := switchFilter(t).Process(Data{ContentType: "application/json", Data: c.input})
+		if err != nil {
+			t.Fatalf("Process failed: %v", err)
+		}
+		if out.String() != c.expected {
+			t.Fatalf("unexpected output: got=%q want=%q", out.String(), c.expected)
+		}
+	}
+}
+
+func TestSwitch_Process_NoMatch(t *testing.T) {
+	sc := &SwitchConfig{}
+	cfg := Config{Params: map[string]any{
+		"Cases": []map[string]any{{"When": "false", "Filters": []map[string]any{}}},
+	}}
+	if err := sc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	out, err := sc.Process(Data{Data: "through"})
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if out.Data != "through" {
+		t.Fatalf("unexpected output: %#v", out.Data)
+	}
+}
+
+func TestSwitch_Prep_InvalidParams(t *testing.T) {
+	sc := &SwitchConfig{}
+	if err := sc.Prep(Config{Params: map[string]any{}}, Data{}); err != ErrMissingParams {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sc = &SwitchConfig{}
+	cfg := Config{Params: map[string]any{"Cases": []map[string]any{{"When": ".[", "Filters": []map[string]any{}}}}}
+	if err := sc.Prep(cfg, Data{}); err == nil {
+		t.Fatalf("expected parse error")
+	}
+}
@@ -2,14 +2,13 @@ package filterweb
 
 import (
 	"bytes"
+	"context"
 	tmplHtml "html/template"
 	"io"
 	"log/slog"
 	"maps"
 	"os"
 	tmplText "text/template"
-
-	"github.com/go-viper/mapstructure/v2"
 )
 
 type TemplateConfig struct {
@@ -36,6 +35,10 @@ func (tc *TemplateConfig) Accepts() []string {
 	return []string{"application/json", "application/yaml", "text/yaml", "text/xml", "application/xml", "text/dotenv"}
 }
 
+func (tc *TemplateConfig) OutputContentType(input string) string {
+	return tc.ContentType
+}
+
 func (tc *TemplateConfig) load(tmpl string) (err error) {
 	funcs := makefuncs()
 	switch tc.Type {
@@ -55,7 +58,7 @@ func (tc *TemplateConfig) load(tmpl string) (err error) {
 func (tc *TemplateConfig) Prep(config Config, data Data) error {
 	// defaults
 	tc.Type = "text"
-	err := mapstructure.Decode(config.Params, tc)
+	err := decodeParams(config.Params, tc)
 	if err != nil {
 		return err
 	}
@@ -89,23 +92,39 @@ func (tc *TemplateConfig) Prep(config Config, data Data) error {
 }
 
 func (tc *TemplateConfig) Process(data Data) (Data, error) {
+	return tc.ProcessContext(context.Background(), data)
+}
+
+func (tc *TemplateConfig) ProcessContext(ctx context.Context, data Data) (Data, error) {
 	res := Data{ContentType: tc.ContentType}
 	wr := &bytes.Buffer{}
 	if tc.BaseKey != "" {
 		data.Data = map[string]any{tc.BaseKey: data.Data}
 	}
 	if dataMap, ok := data.Data.(map[string]any); ok {
+		// input may be shared with other filters (e.g. parallel)
+		dataMap = maps.Clone(dataMap)
 		maps.Copy(dataMap, tc.Vars)
+		data.Data = dataMap
 	} else if len(tc.Vars) > 0 {
 		slog.Warn("ignore vars: input data is not a map", "vars", tc.Vars)
 	}
+	// clone per execution to bind variables of this pipeline
 	if tc.tmplhtml != nil {
-		err := tc.tmplhtml.Execute(wr, data.Data)
+		tmpl, err := tc.tmplhtml.Clone()
+		if err != nil {
+			return res, err
+		}
+		err = tmpl.Funcs(varFuncs(ctx)).Execute(wr, data.Data)
 		if err != nil {
 			return res, err
 		}
 	} else if tc.tmpltxt != nil {
-		err := tc.tmpltxt.Execute(wr, data.Data)
+		tmpl, err := tc.tmpltxt.Clone()
+		if err != nil {
+			return res, err
+		}
+		err = tmpl.Funcs(varFuncs(ctx)).Execute(wr, data.Data)
 		if err != nil {
 			return res, err
 		}
@@ -114,6 +133,36 @@ func (tc *TemplateConfig) Process(data Data) (Data, error) {
 	return res, nil
 }
 
+// parseText parses text template with template functions
+func parseText(name, text string) (*tmplText.Template, error) {
+	return tmplText.New(name).Funcs(makefuncs()).Parse(text)
+}
+
+// varFuncs returns template functions to read variables
+func varFuncs(ctx context.Context) map[string]any {
+	vars := VarsFromContext(ctx)
+	return map[string]any{
+		"var": func(name string) any {
+			v, _ := vars.Get(name)
+			return v
+		},
+		"vars": vars.All,
+	}
+}
+
+// renderText executes text template and returns the result
+func renderText(ctx context.Context, tmpl *tmplText.Template, data any) (string, error) {
+	wr := &bytes.Buffer{}
+	tmpl, err := tmpl.Clone()
+	if err != nil {
+		return "", err
+	}
+	if err := tmpl.Funcs(varFuncs(ctx)).Execute(wr, data); err != nil {
+		return "", err
+	}
+	return wr.String(), nil
+}
+
 func (tc *TemplateConfig) Post(config Config, data Data) error {
 	return nil
 }
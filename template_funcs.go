@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"encoding/xml"
 	"log/slog"
+	"net/url"
 	"regexp"
 	"strconv"
 	"text/template"
@@ -120,19 +121,23 @@ func do_unbase64(data string) []byte {
 
 func makefuncs() template.FuncMap {
 	return template.FuncMap{
-		"match":    match,
-		"capture":  capture,
-		"now":      time.Now,
-		"rfc3339":  rfc3339,
-		"strftime": do_strftime,
-		"strptime": strptime,
-		"in":       in,
-		"toJSON":   tojson,
-		"toYAML":   toyaml,
-		"toXML":    toxml,
-		"hex":      do_hex,
-		"unhex":    do_unhex,
-		"base64":   do_base64,
-		"unbase64": do_unbase64,
+		"match":      match,
+		"capture":    capture,
+		"now":        time.Now,
+		"rfc3339":    rfc3339,
+		"strftime":   do_strftime,
+		"strptime":   strptime,
+		"in":         in,
+		"toJSON":     tojson,
+		"toYAML":     toyaml,
+		"toXML":      toxml,
+		"hex":        do_hex,
+		"unhex":      do_unhex,
+		"base64":     do_base64,
+		"unbase64":   do_unbase64,
+		"pathescape": url.PathEscape,
+		// replaced on execution, see varFuncs
+		"var":  func(string) any { return nil },
+		"vars": func() map[string]any { return map[string]any{} },
 	}
 }
@@ -0,0 +1,98 @@
+package filterweb
+
+import (
+	"bytes"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// tomlCodec is a codec of TOML. integers are decoded as int and date/times as strings of TOML format.
+type tomlCodec struct{}
+
+func (tomlCodec) ContentTypes() []string { return []string{"application/toml"} }
+func (tomlCodec) Extensions() []string   { return []string{"toml"} }
+
+// normalizeTOML converts decoded values to types used by jq and templates
+func normalizeTOML(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, e := range val {
+			val[k] = normalizeTOML(e)
+		}
+		return val
+	case []map[string]any:
+		res := make([]any, len(val))
+		for i, e := range val {
+			res[i] = normalizeTOML(e)
+		}
+		return res
+	case []any:
+		for i, e := range val {
+			val[i] = normalizeTOML(e)
+		}
+		return val
+	case int64:
+		return int(val)
+	case time.Time:
+		// local date/time has a location named by the decoder
+		switch val.Location().String() {
+		case "date-local":
+			return val.Format(time.DateOnly)
+		case "time-local":
+			return val.Format("15:04:05.999999999")
+		case "datetime-local":
+			return val.Format("2006-01-02T15:04:05.999999999")
+		}
+		return val.Format(time.RFC3339Nano)
+	}
+	return v
+}
+
+func (tomlCodec) Decode(data []byte) (any, error) {
+	res := map[string]any{}
+	if err := toml.Unmarshal(data, &res); err != nil {
+		slog.Error("toml decode error", "error", err)
+		return nil, err
+	}
+	return normalizeTOML(res), nil
+}
+
+// tomlValue converts integral float64 (e.g. decoded from JSON) to int64,
+// which would be encoded as float otherwise
+func tomlValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		res := make(map[string]any, len(val))
+		for k, e := range val {
+			res[k] = tomlValue(e)
+		}
+		return res
+	case []any:
+		res := make([]any, len(val))
+		for i, e := range val {
+			res[i] = tomlValue(e)
+		}
+		return res
+	case float64:
+		if val == math.Trunc(val) && math.Abs(val) < 1<<63 {
+			return int64(val)
+		}
+	}
+	return v
+}
+
+func (tomlCodec) Encode(data any) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := toml.NewEncoder(buf).Encode(tomlValue(data)); err != nil {
+		slog.Error("toml encode error", "error", err, "data", data)
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func init() {
+	RegisterCodec(tomlCodec{})
+}
@@ -0,0 +1,86 @@
+package filterweb
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+type TryConfig struct {
+	Filter
+	Filters []Config // filters to try
+	Catch   []Config // filters to process on error, input is the error object
+	body    *Pipeline
+	catch   *Pipeline
+}
+
+func (tc *TryConfig) New() Filter {
+	return &TryConfig{}
+}
+
+func (tc *TryConfig) Name() string {
+	return "try"
+}
+
+func (tc *TryConfig) Accepts() []string {
+	return []string{}
+}
+
+func (tc *TryConfig) Prep(config Config, data Data) error {
+	err := decodeParams(config.Params, tc)
+	if err != nil {
+		return err
+	}
+	// mandatory
+	if len(tc.Filters) == 0 {
+		slog.Error("try filter requires 'filters' parameter")
+		return ErrMissingParams
+	}
+	if tc.body, err = Compile(tc.Filters); err != nil {
+		return err
+	}
+	if tc.catch, err = Compile(tc.Catch); err != nil {
+		return fmt.Errorf("catch: %w", err)
+	}
+	return nil
+}
+
+func (tc *TryConfig) OutputContentType(input string) string {
+	body := tc.body.OutputContentType(input)
+	if body != tc.catch.OutputContentType("application/json") {
+		return ""
+	}
+	return body
+}
+
+func (tc *TryConfig) subPipelines() []*Pipeline {
+	return []*Pipeline{tc.body, tc.catch}
+}
+
+func (tc *TryConfig) Process(data Data) (Data, error) {
+	return tc.ProcessContext(context.Background(), data)
+}
+
+func (tc *TryConfig) ProcessContext(ctx context.Context, data Data) (Data, error) {
+	res, err := tc.body.Process(ctx, data)
+	if err == nil {
+		return res, nil
+	}
+	if ctx.Err() != nil {
+		// cancelled or timed out, no chance to recover
+		return res, err
+	}
+	errdata := ErrorData(err)
+	errdata["input"] = normalize(data.Data)
+	slog.Warn("caught error", "error", errdata)
+	errres := Data{ContentType: "application/json", Data: errdata, Response: data.Response}
+	return tc.catch.Process(ctx, errres)
+}
+
+func (tc *TryConfig) Post(config Config, data Data) error {
+	return nil
+}
+
+func init() {
+	RegisterFilter(&TryConfig{})
+}
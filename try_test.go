@@ -0,0 +1,92 @@
+package filterweb
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTry_Process_NoError(t *testing.T) {
+	tc := &TryConfig{}
+	cfg := Config{Params: map[string]any{
+		"Filters": []map[string]any{{"Name": "constant", "Params": map[string]any{"Data": "ok"}}},
+		"Catch":   []map[string]any{{"Name": "constant", "Params": map[string]any{"Data": "ng"}}},
+	}}
+	if err := tc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	out, err := tc.Process(Data{})
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if out.String() != "ok" {
+		t.Fatalf("unexpected output: %v", out)
+	}
+}
+
+func TestTry_Process_CatchHTTPStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, "not found")
+	}))
+	defer srv.Close()
+
+	tc := &TryConfig{}
+	cfg := Config{Params: map[string]any{
+		"Filters": []map[string]any{
+			{"Name": "constant", "Params": map[string]any{"Data": "x"}},
+			{"Name": "http", "Params": map[string]any{"Url": srv.URL}},
+		},
+		"Catch": []map[string]any{
+			{"Name": "jq", "Params": map[string]any{"Expression": "{filter, index, kind, status}"}},
+		},
+	}}
+	if err := tc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	out, err := tc.Process(Data{})
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	res := out.Data.([]any)[0].(map[string]any)
+	if res["filter"] != "http" || res["index"] != 1 || res["kind"] != "ErrHTTPStatusNotOK" || res["status"] != 404 {
+		t.Fatalf("unexpected error object: %#v", res)
+	}
+}
+
+func TestTry_Process_NoCatch(t *testing.T) {
+	tc := &TryConfig{}
+	cfg := Config{Params: map[string]any{
+		"Filters": []map[string]any{{"Name": "jq", "Params": map[string]any{"Expression": `error("bad")`}}},
+	}}
+	if err := tc.Prep(cfg, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	out, err := tc.Process(Data{ContentType: "application/json", Data: "in"})
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	res := out.Data.(map[string]any)
+	if res["filter"] != "jq" || res["index"] != 0 || res["input"] != "in" {
+		t.Fatalf("unexpected error object: %#v", res)
+	}
+}
+
+func TestTry_Prep_FilterNotFound(t *testing.T) {
+	tc := &TryConfig{}
+	cfg := Config{Params: map[string]any{
+		"Filters": []map[string]any{{"Name": "nonexistent"}},
+	}}
+	if err := tc.Prep(cfg, Data{}); !errors.Is(err, ErrFilterNotFound) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestTry_Prep_MissingParams(t *testing.T) {
+	tc := &TryConfig{}
+	if err := tc.Prep(Config{Params: map[string]any{}}, Data{}); err != ErrMissingParams {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package filterweb
+
+import (
+	"context"
+	"maps"
+	"sync"
+)
+
+// Vars is a variable store of a pipeline execution.
+// sub-pipelines (foreach, parallel) have their own scope, lookups fall back to the parent.
+type Vars struct {
+	mu     sync.RWMutex
+	parent *Vars
+	values map[string]any
+}
+
+type varsKey struct{}
+
+// WithVars returns context with new variable scope, initialized by values
+func WithVars(ctx context.Context, values map[string]any) context.Context {
+	vars := &Vars{parent: VarsFromContext(ctx), values: maps.Clone(values)}
+	if vars.values == nil {
+		vars.values = map[string]any{}
+	}
+	return context.WithValue(ctx, varsKey{}, vars)
+}
+
+// VarsFromContext returns variable store of the context, or nil
+func VarsFromContext(ctx context.Context) *Vars {
+	vars, _ := ctx.Value(varsKey{}).(*Vars)
+	return vars
+}
+
+func (v *Vars) Get(name string) (any, bool) {
+	for ; v != nil; v = v.parent {
+		v.mu.RLock()
+		val, ok := v.values[name]
+		v.mu.RUnlock()
+		if ok {
+			return val, true
+		}
+	}
+	return nil, false
+}
+
+func (v *Vars) Set(name string, value any) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.values[name] = value
+}
+
+// All returns all visible variables
+func (v *Vars) All() map[string]any {
+	res := map[string]any{}
+	if v == nil {
+		return res
+	}
+	maps.Copy(res, v.parent.All())
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	maps.Copy(res, v.values)
+	return res
+}
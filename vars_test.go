@@ -0,0 +1,88 @@
+package filterweb
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestVars_Scope(t *testing.T) {
+	ctx := WithVars(context.Background(), map[string]any{"a": 1, "b": 2})
+	child := WithVars(ctx, map[string]any{"b": 3})
+	VarsFromContext(child).Set("c", 4)
+	if v, ok := VarsFromContext(child).Get("a"); !ok || v != 1 {
+		t.Fatalf("unexpected a: %v", v)
+	}
+	all := VarsFromContext(child).All()
+	if all["b"] != 3 || all["c"] != 4 || len(all) != 3 {
+		t.Fatalf("unexpected vars: %#v", all)
+	}
+	if _, ok := VarsFromContext(ctx).Get("c"); ok {
+		t.Fatalf("child variable leaks to parent")
+	}
+}
+
+func TestProcessFiltersContext_Vars(t *testing.T) {
+	ctx := WithVars(context.Background(), map[string]any{"request": map[string]any{"name": "Alice"}})
+	out, err := ProcessFiltersContext(ctx, []Config{
+		{Name: "constant", Params: map[string]any{"ContentType": "application/json", "Data": `{"id": 1, "items": [1, 2]}`}},
+		{Name: "set", Params: map[string]any{"Var": "id", "Expression": ".id"}},
+		{Name: "jq", Params: map[string]any{"Expression": "{name: $request.name, id: $id, count: (.items | length)}"}},
+		{Name: "template", Params: map[string]any{"Content": `{{range .}}{{.name}} {{var "id"}} {{.count}}{{end}}`}},
+	}, Data{})
+	if err != nil {
+		t.Fatalf("ProcessFiltersContext failed: %v", err)
+	}
+	if out.String() != "Alice 1 2" {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
+
+func TestSet_Prep_MissingParams(t *testing.T) {
+	sc := &SetConfig{}
+	if err := sc.Prep(Config{Params: map[string]any{}}, Data{}); err != ErrMissingParams {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestSet_Process_WholeData(t *testing.T) {
+	sc := &SetConfig{}
+	if err := sc.Prep(Config{Params: map[string]any{"Var": "all"}}, Data{}); err != nil {
+		t.Fatalf("Prep failed: %v", err)
+	}
+	ctx := WithVars(context.Background(), nil)
+	out, err := sc.ProcessContext(ctx, Data{Data: []byte("raw")})
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	if string(out.Data.([]byte)) != "raw" {
+		t.Fatalf("data is not passed through: %#v", out.Data)
+	}
+	if v, _ := VarsFromContext(ctx).Get("all"); v != "raw" {
+		t.Fatalf("unexpected variable: %#v", v)
+	}
+}
+
+func TestRunQuery_Vars(t *testing.T) {
+	query, err := compileQuery(`[$a, $missing, (. as $x | $x), "$a", $ENV.PATH == null, "\($a)"]`)
+	if err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+	if !reflect.DeepEqual(query.vars, []string{"a", "missing"}) {
+		t.Fatalf("unexpected variables: %v", query.vars)
+	}
+	for _, a := range []any{1, "x"} {
+		ctx := WithVars(context.Background(), map[string]any{"a": a})
+		outs, err := runQuery(ctx, query, 5)
+		if err != nil {
+			t.Fatalf("run failed: %v", err)
+		}
+		res := outs[0].([]any)
+		if res[0] != a || res[1] != nil || res[2] != 5 || res[3] != "$a" || res[4] != true {
+			t.Fatalf("unexpected result: %v", res)
+		}
+	}
+	if outs, err := runQuery(context.Background(), query, 5); err != nil || outs[0].([]any)[0] != nil {
+		t.Fatalf("unexpected result without vars: %v %v", outs, err)
+	}
+}
@@ -0,0 +1,303 @@
+package filterweb
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// xmlCodec maps XML document to generic tree and back.
+//
+// the document is a map with the root element name as a key. an element is
+// a string of its text when it has no attributes and no children, otherwise a map:
+// attributes are keyed with "@" prefix, text with "#text", and child elements with
+// its name. repeated children become an array. namespace prefixes are kept as a part
+// of names (e.g. "soap:Envelope", "@xmlns:soap").
+//
+// when the order of children matters, that is, the element has text around its children
+// (mixed content) or a repeated child is separated by other children, the children are
+// kept in order as "#content", an array of text strings and maps of single child element.
+// comments and processing instructions are dropped.
+type xmlCodec struct{}
+
+const (
+	xmlAttrPrefix = "@"
+	xmlTextKey    = "#text"
+	xmlContentKey = "#content"
+	xmlRootName   = "root" // root element of data other than a map with single key
+)
+
+func (xmlCodec) ContentTypes() []string { return []string{"application/xml", "text/xml"} }
+func (xmlCodec) Extensions() []string   { return []string{"xml"} }
+
+// xmlElement is an element being decoded
+type xmlElement struct {
+	name    string
+	value   map[string]any // attributes
+	content []any          // text strings and children in order
+}
+
+// xmlChild is a child element in content
+type xmlChild struct {
+	name  string
+	value any
+}
+
+func rawName(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+	return n.Space + ":" + n.Local
+}
+
+// addChild adds value to the map, repeated keys become an array
+func addChild(m map[string]any, key string, value any) {
+	prev, ok := m[key]
+	if !ok {
+		m[key] = value
+		return
+	}
+	if arr, ok := prev.([]any); ok {
+		m[key] = append(arr, value)
+	} else {
+		m[key] = []any{prev, value}
+	}
+}
+
+// addText appends text to the content
+func (e *xmlElement) addText(text string) {
+	if n := len(e.content); n != 0 {
+		if prev, ok := e.content[n-1].(string); ok {
+			e.content[n-1] = prev + text
+			return
+		}
+	}
+	e.content = append(e.content, text)
+}
+
+// result returns decoded value of the element
+func (e *xmlElement) result() any {
+	var text strings.Builder
+	var children []xmlChild
+	interleaved := false
+	seen := map[string]bool{}
+	for _, item := range e.content {
+		switch v := item.(type) {
+		case string:
+			text.WriteString(v)
+		case xmlChild:
+			if seen[v.name] && children[len(children)-1].name != v.name {
+				interleaved = true
+			}
+			seen[v.name] = true
+			children = append(children, v)
+		}
+	}
+	if len(children) == 0 {
+		if len(e.value) == 0 {
+			return text.String()
+		}
+		if strings.TrimSpace(text.String()) != "" {
+			e.value[xmlTextKey] = text.String()
+		}
+		return e.value
+	}
+	mixed := strings.TrimSpace(text.String()) != ""
+	if !mixed && !interleaved {
+		for _, c := range children {
+			addChild(e.value, c.name, c.value)
+		}
+		return e.value
+	}
+	content := []any{}
+	for _, item := range e.content {
+		switch v := item.(type) {
+		case string:
+			// whitespaces between elements are significant only in mixed content
+			if mixed {
+				content = append(content, v)
+			}
+		case xmlChild:
+			content = append(content, map[string]any{v.name: v.value})
+		}
+	}
+	e.value[xmlContentKey] = content
+	return e.value
+}
+
+func (xmlCodec) Decode(data []byte) (any, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var stack []*xmlElement
+	var res map[string]any
+	for {
+		tok, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			slog.Error("xml decode error", "error", err)
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			elem := &xmlElement{name: rawName(t.Name), value: map[string]any{}}
+			for _, attr := range t.Attr {
+				elem.value[xmlAttrPrefix+rawName(attr.Name)] = attr.Value
+			}
+			stack = append(stack, elem)
+		case xml.EndElement:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("%w: unexpected end element %s", ErrDecode, rawName(t.Name))
+			}
+			elem := stack[len(stack)-1]
+			if name := rawName(t.Name); name != elem.name {
+				slog.Error("xml element mismatch", "start", elem.name, "end", name)
+				return nil, fmt.Errorf("%w: element <%s> closed by </%s>", ErrDecode, elem.name, name)
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				if res != nil {
+					return nil, fmt.Errorf("%w: multiple root elements", ErrDecode)
+				}
+				res = map[string]any{elem.name: elem.result()}
+			} else {
+				parent := stack[len(stack)-1]
+				parent.content = append(parent.content, xmlChild{name: elem.name, value: elem.result()})
+			}
+		case xml.CharData:
+			if len(stack) != 0 {
+				stack[len(stack)-1].addText(string(t))
+			}
+		}
+	}
+	if len(stack) != 0 || res == nil {
+		return nil, fmt.Errorf("%w: incomplete xml document", ErrDecode)
+	}
+	return res, nil
+}
+
+func (xmlCodec) Encode(data any) ([]byte, error) {
+	var root map[string]any
+	switch val := normalize(data).(type) {
+	case map[string]any:
+		root = val
+		for _, v := range val {
+			if _, ok := normalize(v).([]any); ok {
+				// an array would be multiple root elements
+				root = nil
+			}
+		}
+		if len(root) != 1 {
+			root = map[string]any{xmlRootName: val}
+		}
+	case []any, string, float64, int, int64, bool, nil:
+		root = map[string]any{xmlRootName: val}
+	default:
+		// typed data with xml tags
+		return xml.Marshal(data)
+	}
+	buf := &bytes.Buffer{}
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(buf)
+	for name, value := range root {
+		if err := encodeElement(encoder, name, value); err != nil {
+			slog.Error("xml encode error", "error", err)
+			return nil, err
+		}
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeElement writes value as element(s) of name
+func encodeElement(encoder *xml.Encoder, name string, value any) error {
+	if strings.HasPrefix(name, xmlAttrPrefix) || name == xmlTextKey || name == xmlContentKey || name == "" {
+		return fmt.Errorf("%w: invalid element name %q", ErrEncode, name)
+	}
+	value = normalize(value)
+	if arr, ok := value.([]any); ok {
+		for _, v := range arr {
+			if err := encodeElement(encoder, name, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	m, ok := value.(map[string]any)
+	if !ok {
+		if err := encoder.EncodeToken(start); err != nil {
+			return err
+		}
+		if value != nil {
+			if err := encoder.EncodeToken(xml.CharData(fmt.Sprint(value))); err != nil {
+				return err
+			}
+		}
+		return encoder.EncodeToken(start.End())
+	}
+	keys := slices.Sorted(maps.Keys(m))
+	for _, k := range keys {
+		if attr, ok := strings.CutPrefix(k, xmlAttrPrefix); ok {
+			start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: attr}, Value: fmt.Sprint(m[k])})
+		}
+	}
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+	if text, ok := m[xmlTextKey]; ok && text != nil {
+		if err := encoder.EncodeToken(xml.CharData(fmt.Sprint(text))); err != nil {
+			return err
+		}
+	}
+	if content, ok := m[xmlContentKey]; ok {
+		if err := encodeContent(encoder, content); err != nil {
+			return err
+		}
+	}
+	for _, k := range keys {
+		if strings.HasPrefix(k, xmlAttrPrefix) || k == xmlTextKey || k == xmlContentKey {
+			continue
+		}
+		if err := encodeElement(encoder, k, m[k]); err != nil {
+			return err
+		}
+	}
+	return encoder.EncodeToken(start.End())
+}
+
+// encodeContent writes ordered text and child elements
+func encodeContent(encoder *xml.Encoder, content any) error {
+	items, ok := normalize(content).([]any)
+	if !ok {
+		return fmt.Errorf("%w: %s is not an array", ErrEncode, xmlContentKey)
+	}
+	for _, item := range items {
+		switch v := normalize(item).(type) {
+		case string:
+			if err := encoder.EncodeToken(xml.CharData(v)); err != nil {
+				return err
+			}
+		case map[string]any:
+			for _, k := range slices.Sorted(maps.Keys(v)) {
+				if err := encodeElement(encoder, k, v[k]); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("%w: invalid item in %s: %v", ErrEncode, xmlContentKey, item)
+		}
+	}
+	return nil
+}
+
+func init() {
+	RegisterCodec(xmlCodec{})
+}
@@ -0,0 +1,191 @@
+package filterweb
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const soapDoc = `<?xml version="1.0" encoding="UTF-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <items count="2">
+      <item id="1">apple</item>
+      <item id="2">banana</item>
+      <note>fresh</note>
+      <empty/>
+    </items>
+  </soap:Body>
+</soap:Envelope>`
+
+func TestXML_Decode(t *testing.T) {
+	res, err := DecodeContentType("application/xml", []byte(soapDoc))
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	expected := map[string]any{
+		"soap:Envelope": map[string]any{
+			"@xmlns:soap": "http://schemas.xmlsoap.org/soap/envelope/",
+			"soap:Body": map[string]any{
+				"items": map[string]any{
+					"@count": "2",
+					"item": []any{
+						map[string]any{"@id": "1", "#text": "apple"},
+						map[string]any{"@id": "2", "#text": "banana"},
+					},
+					"note":  "fresh",
+					"empty": "",
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(res, expected) {
+		t.Fatalf("unexpected result: %#v", res)
+	}
+}
+
+func TestXML_RoundTrip(t *testing.T) {
+	res, err := DecodeContentType("text/xml", []byte(soapDoc))
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	buf, err := EncodeContentType("text/xml", res)
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	if !strings.Contains(string(buf), `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">`) {
+		t.Fatalf("namespace is lost: %s", buf)
+	}
+	again, err := DecodeContentType("text/xml", buf)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if !reflect.DeepEqual(res, again) {
+		t.Fatalf("round trip failed: %#v != %#v", res, again)
+	}
+}
+
+func TestXML_Encode_FromJSON(t *testing.T) {
+	var in any
+	if err := json.Unmarshal([]byte(`{"users":{"user":[{"@id":1,"name":"Alice"},{"@id":2,"name":"Bob"}]}}`), &in); err != nil {
+		t.Fatalf("json failed: %v", err)
+	}
+	buf, err := EncodeContentType("application/xml", in)
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	expected := `<users><user id="1"><name>Alice</name></user><user id="2"><name>Bob</name></user></users>`
+	if !strings.HasSuffix(string(buf), expected) {
+		t.Fatalf("unexpected xml: %s", buf)
+	}
+}
+
+func TestXML_Encode_MultipleKeys(t *testing.T) {
+	buf, err := EncodeContentType("application/xml", map[string]any{"a": 1, "b": "x<y"})
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	if !strings.HasSuffix(string(buf), `<root><a>1</a><b>x&lt;y</b></root>`) {
+		t.Fatalf("unexpected xml: %s", buf)
+	}
+}
+
+func TestXML_Decode_Invalid(t *testing.T) {
+	if _, err := DecodeContentType("application/xml", []byte("<a><b></a>")); err == nil {
+		t.Fatalf("expected error")
+	}
+	for _, src := range []string{"<a><b>1</a></b>", "<a><b>1</b></c>", "<x:a></y:a>"} {
+		if _, err := DecodeContentType("application/xml", []byte(src)); !errors.Is(err, ErrDecode) {
+			t.Fatalf("expected decode error for %s: %v", src, err)
+		}
+	}
+}
+
+func TestXML_Encode_SingleKeyArray(t *testing.T) {
+	var in any
+	if err := json.Unmarshal([]byte(`{"item":[1,2]}`), &in); err != nil {
+		t.Fatalf("json failed: %v", err)
+	}
+	buf, err := EncodeContentType("application/xml", in)
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	if !strings.HasSuffix(string(buf), `<root><item>1</item><item>2</item></root>`) {
+		t.Fatalf("unexpected xml: %s", buf)
+	}
+	if _, err := DecodeContentType("application/xml", buf); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+}
+
+func TestXML_MixedContent(t *testing.T) {
+	src := `<p>Hello <b>world</b> and <i>you</i>!</p>`
+	res, err := DecodeContentType("application/xml", []byte(src))
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	expected := map[string]any{"p": map[string]any{"#content": []any{
+		"Hello ", map[string]any{"b": "world"}, " and ", map[string]any{"i": "you"}, "!",
+	}}}
+	if !reflect.DeepEqual(res, expected) {
+		t.Fatalf("unexpected result: %#v", res)
+	}
+	buf, err := EncodeContentType("application/xml", res)
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	if !strings.HasSuffix(string(buf), src) {
+		t.Fatalf("unexpected xml: %s", buf)
+	}
+}
+
+func TestXML_SiblingOrder(t *testing.T) {
+	src := `<a id="1"><x>1</x><y>2</y><x>3</x></a>`
+	res, err := DecodeContentType("application/xml", []byte(src))
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	expected := map[string]any{"a": map[string]any{"@id": "1", "#content": []any{
+		map[string]any{"x": "1"}, map[string]any{"y": "2"}, map[string]any{"x": "3"},
+	}}}
+	if !reflect.DeepEqual(res, expected) {
+		t.Fatalf("unexpected result: %#v", res)
+	}
+	buf, err := EncodeContentType("application/xml", res)
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	if !strings.HasSuffix(string(buf), src) {
+		t.Fatalf("unexpected xml: %s", buf)
+	}
+}
+
+func TestXML_Namespace(t *testing.T) {
+	src := `<feed xmlns="http://www.w3.org/2005/Atom" xmlns:x="urn:x"><title>t</title><x:entry x:id="1">a</x:entry></feed>`
+	res, err := DecodeContentType("application/xml", []byte(src))
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	expected := map[string]any{"feed": map[string]any{
+		"@xmlns":   "http://www.w3.org/2005/Atom",
+		"@xmlns:x": "urn:x",
+		"title":    "t",
+		"x:entry":  map[string]any{"@x:id": "1", "#text": "a"},
+	}}
+	if !reflect.DeepEqual(res, expected) {
+		t.Fatalf("unexpected result: %#v", res)
+	}
+	buf, err := EncodeContentType("application/xml", res)
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	again, err := DecodeContentType("application/xml", buf)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if !reflect.DeepEqual(res, again) {
+		t.Fatalf("round trip failed: %s", buf)
+	}
+}